@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"log"
+	"net/http"
 	"newstrix/internal/api"
 	"newstrix/internal/config"
 	"newstrix/internal/embedding"
@@ -38,14 +40,72 @@ func main() {
 
 	storageFacade := newStorageFacade(pool)
 
-	embedder, err := embedding.NewEmbedder(cfg.EmbedderURL)
+	dialOpts, err := embedding.DialOptions(embedding.ClientTransport{
+		TLS:        cfg.EmbedderTLS,
+		CAFile:     cfg.EmbedderTLSCA,
+		CertFile:   cfg.EmbedderTLSCert,
+		KeyFile:    cfg.EmbedderTLSKey,
+		ServerName: cfg.EmbedderTLSServerName,
+		Token:      cfg.EmbedderToken,
+	})
+	if err != nil {
+		log.Fatalf("error configuring embedder transport: %v", err)
+	}
+
+	embedderOpts := []embedding.Option{
+		embedding.WithDialOptions(dialOpts...),
+		embedding.WithPriority(embedding.PriorityInteractive),
+		embedding.WithBalancing(embedding.BalancerConfig{
+			Strategy:         cfg.EmbedBalancer,
+			BreakerThreshold: cfg.EmbedBreakerThreshold,
+			BreakerCooldown:  cfg.EmbedBreakerCooldown,
+		}),
+	}
+	if cfg.EmbedCacheEnabled {
+		var store embedding.CacheStore
+		if cfg.EmbedCachePersistent {
+			store = storageFacade
+		}
+		cache := embedding.NewCache(store, cfg.EmbedCacheSize, cfg.EmbedCacheTTL)
+		expvar.Publish("embedding_cache", expvar.Func(func() interface{} { return cache.Stats() }))
+		embedderOpts = append(embedderOpts, embedding.WithCache(cache))
+	}
+
+	embedder, err := embedding.NewEmbedder(cfg.EmbedderURLs, embedderOpts...)
 	if err != nil {
 		log.Fatalf("error connect to embed-service: %v", err)
 	}
+	expvar.Publish("embedding_backends", expvar.Func(func() interface{} { return embedder.BackendStats() }))
+	go embedder.StartHealthProbe(ctx, cfg.EmbedHealthInterval)
 
-	searchEngine := search.NewSearchEngine(ctx, embedder, storageFacade)
+	metric, err := search.ParseMetric(cfg.VectorMetric)
+	if err != nil {
+		log.Fatalf("invalid VECTOR_METRIC: %v", err)
+	}
+
+	if cfg.CursorSecret == "" {
+		log.Println("CURSOR_SECRET is not set, search cursors will not survive a restart")
+	}
+	searchEngine := search.NewSearchEngine(ctx, embedder, storageFacade,
+		search.WithHybrid(search.HybridConfig{
+			LexicalWeight: cfg.HybridLexicalWeight,
+			VectorWeight:  cfg.HybridVectorWeight,
+			K:             cfg.HybridRRFK,
+		}),
+		search.WithMetric(metric),
+		search.WithCursorSecret([]byte(cfg.CursorSecret)),
+	)
+
+	if cfg.ApiMetricsAddress != "" {
+		go func() {
+			log.Printf("Serving API metrics on %s/debug/vars", cfg.ApiMetricsAddress)
+			if err := http.ListenAndServe(cfg.ApiMetricsAddress, expvar.Handler()); err != nil {
+				log.Printf("Metrics server failed: %v", err)
+			}
+		}()
+	}
 
-	router := api.SetupRouter(searchEngine)
+	router := api.SetupRouter(searchEngine, storageFacade)
 
 	log.Printf("Starting API server at %s...", cfg.ApiAddress)
 	err = router.Run(cfg.ApiAddress)
@@ -1,30 +1,198 @@
 package main
 
 import (
+	"expvar"
 	"log"
 	"net"
+	"net/http"
 	"newstrix/internal/config"
 	"newstrix/internal/embedding"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"newstrix/internal/embedding/proto"
+	"strings"
+	"sync"
+)
+
+// dimensionProbe is embedded to learn the dimension of the model and to check
+// that the model is usable.
+const dimensionProbe = "dimension probe"
+
+const (
+	probeTimeout = 10 * time.Second
+	// shutdownTimeout bounds GracefulStop; in-flight calls still running
+	// after it are cut off.
+	shutdownTimeout = 15 * time.Second
 )
 
 type server struct {
 	pb.UnimplementedEmbedderServer
-	ollama *embedding.OllamaClient
+	provider embedding.Provider
+	model    string
+	// expectedDimension is the dimension the model must produce, 0 if any.
+	expectedDimension int
+
+	mu        sync.Mutex
+	dimension int
 }
 
 func (s *server) Embed(ctx context.Context, req *pb.EmbedRequest) (*pb.EmbedResponse, error) {
-	vector, err := s.ollama.Embed(ctx, req.Text)
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, status.Error(codes.InvalidArgument, "text is empty")
+	}
+
+	vector, err := s.provider.Embed(ctx, req.Text)
 	if err != nil {
-		return nil, err // TODO hide error to out format
+		log.Printf("Embed failed: %v", err)
+		return nil, embedding.Status(err)
 	}
 	return &pb.EmbedResponse{Vector: vector}, nil
 }
 
+// EmbedBatch forwards all texts to the provider in one request. If that request
+// fails, every item is embedded on its own so that one bad input does not fail
+// the whole batch. When the provider is unreachable the whole call fails
+// instead.
+func (s *server) EmbedBatch(ctx context.Context, req *pb.EmbedBatchRequest) (*pb.EmbedBatchResponse, error) {
+	results := make([]*pb.EmbedBatchResult, len(req.Items))
+	texts := make([]string, 0, len(req.Items))
+	pending := make([]*pb.EmbedBatchResult, 0, len(req.Items))
+	for i, item := range req.Items {
+		results[i] = &pb.EmbedBatchResult{Id: item.Id}
+		if strings.TrimSpace(item.Text) == "" {
+			setResultError(results[i], status.Error(codes.InvalidArgument, "text is empty"))
+			continue
+		}
+		texts = append(texts, item.Text)
+		pending = append(pending, results[i])
+	}
+	if len(texts) == 0 {
+		return &pb.EmbedBatchResponse{Results: results}, nil
+	}
+
+	vectors, err := s.provider.EmbedBatch(ctx, texts)
+	if err == nil {
+		for i, result := range pending {
+			result.Vector = vectors[i]
+		}
+		return &pb.EmbedBatchResponse{Results: results}, nil
+	}
+
+	log.Printf("Batch of %d items failed: %v", len(texts), err)
+	// Items fail one by one only when the input may be at fault.
+	if st := embedding.Status(err); embedding.IsRetryable(st) || status.Code(st) == codes.FailedPrecondition {
+		return nil, st
+	}
+
+	for i, text := range texts {
+		vector, err := s.provider.Embed(ctx, text)
+		if err != nil {
+			log.Printf("Embedding item %s failed: %v", pending[i].Id, err)
+			setResultError(pending[i], embedding.Status(err))
+			continue
+		}
+		pending[i].Vector = vector
+	}
+	return &pb.EmbedBatchResponse{Results: results}, nil
+}
+
+func setResultError(result *pb.EmbedBatchResult, err error) {
+	st := status.Convert(err)
+	result.Code = int32(st.Code())
+	result.Error = st.Message()
+}
+
+// Info reports the configured model. Its dimension is learned from the first
+// successful probe embedding.
+func (s *server) Info(ctx context.Context, _ *pb.InfoRequest) (*pb.InfoResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dimension == 0 {
+		dimension, err := s.probe(ctx)
+		if err != nil {
+			log.Printf("Dimension probe failed: %v", err)
+			return nil, embedding.Status(err)
+		}
+		s.dimension = dimension
+	}
+
+	return &pb.InfoResponse{Model: s.model, Dimension: int32(s.dimension)}, nil
+}
+
+// probe embeds dimensionProbe and returns the dimension of the vector. It
+// fails when the model is unreachable or produces vectors of another
+// dimension than expected.
+func (s *server) probe(ctx context.Context) (int, error) {
+	vector, err := s.provider.Embed(ctx, dimensionProbe)
+	if err != nil {
+		return 0, err
+	}
+	if s.expectedDimension > 0 && len(vector) != s.expectedDimension {
+		return 0, status.Errorf(codes.FailedPrecondition, "model %s produces %d-dimensional vectors, expected %d",
+			s.model, len(vector), s.expectedDimension)
+	}
+	return len(vector), nil
+}
+
+// watchHealth probes the model every interval and reports the result through
+// the health service, for the whole server and for the Embedder service.
+func (s *server) watchHealth(ctx context.Context, hs *health.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	serving := false
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		dimension, err := s.probe(probeCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			next = healthpb.HealthCheckResponse_NOT_SERVING
+			if serving {
+				log.Printf("Model %s is not usable: %v", s.model, err)
+			}
+		} else {
+			s.mu.Lock()
+			s.dimension = dimension
+			s.mu.Unlock()
+			if !serving {
+				log.Printf("Model %s is ready, dimension %d", s.model, dimension)
+			}
+		}
+		serving = err == nil
+		hs.SetServingStatus("", next)
+		hs.SetServingStatus(pb.Embedder_ServiceDesc.ServiceName, next)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
 	cfg := config.Load()
 
@@ -33,16 +201,84 @@ func main() {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	ollamaClnt := embedding.NewOllamaClient(cfg.OllamaURL, cfg.OllamaModel)
+	provider, err := embedding.NewProvider(embedding.ProviderConfig{
+		Kind:          cfg.EmbedProvider,
+		OllamaURL:     cfg.OllamaURL,
+		OllamaModel:   cfg.OllamaModel,
+		OpenAIURL:     cfg.OpenAIURL,
+		OpenAIModel:   cfg.OpenAIModel,
+		OpenAIAPIKey:  cfg.OpenAIAPIKey,
+		HashDimension: cfg.HashDimension,
+	})
+	if err != nil {
+		log.Fatalf("failed to create embedding provider: %v", err)
+	}
 
-	grpcServer := grpc.NewServer()
+	serverOpts, err := embedding.ServerOptions(embedding.ServerTransport{
+		CertFile:     cfg.GrpcTLSCert,
+		KeyFile:      cfg.GrpcTLSKey,
+		ClientCAFile: cfg.GrpcTLSClientCA,
+		Token:        cfg.EmbedderToken,
+	})
+	if err != nil {
+		log.Fatalf("failed to configure transport: %v", err)
+	}
+
+	if cfg.EmbedMaxConcurrency > 0 {
+		limiter := embedding.NewLimiter(cfg.EmbedMaxConcurrency, cfg.EmbedMaxQueue)
+		expvar.Publish("embedder_limiter", expvar.Func(func() interface{} { return limiter.Stats() }))
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(embedding.UnaryLimitInterceptor(limiter)))
+	}
+
+	if cfg.MetricsAddress != "" {
+		go func() {
+			log.Printf("Serving embedder metrics on %s/debug/vars", cfg.MetricsAddress)
+			if err := http.ListenAndServe(cfg.MetricsAddress, expvar.Handler()); err != nil {
+				log.Printf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
 	s := &server{
-		ollama: ollamaClnt,
+		provider:          provider,
+		model:             cfg.EmbeddingModel(),
+		expectedDimension: cfg.EmbedDimension,
 	}
 	pb.RegisterEmbedderServer(grpcServer, s)
 
-	log.Printf("Embedder gRPC server running on %s \n", cfg.GrpcAddress)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(pb.Embedder_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go s.watchHealth(ctx, healthServer, cfg.EmbedHealthInterval)
+
+	if cfg.GrpcReflection {
+		reflection.Register(grpcServer)
+	}
+
+	go func() {
+		<-sig
+		log.Println("Received termination signal, shutting down...")
+		cancel()
+		healthServer.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			log.Println("Graceful shutdown timed out, closing remaining connections")
+			grpcServer.Stop()
+		}
+	}()
+
+	log.Printf("Embedder gRPC server running on %s with model %s\n", cfg.GrpcAddress, cfg.EmbeddingModel())
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
+	log.Println("Embedder stopped gracefully")
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"log"
+	"newstrix/internal/chunk"
+	"newstrix/internal/cluster"
 	"newstrix/internal/config"
 	"newstrix/internal/embedding"
 	"newstrix/internal/fetch"
+	"newstrix/internal/fetch/extract"
 	"newstrix/internal/fetch/sources"
-	"newstrix/internal/models"
 	"newstrix/internal/storage"
 	"newstrix/internal/storage/postgres"
 	"os"
@@ -31,17 +33,26 @@ func main() {
 
 	cfg := config.Load()
 
-	srcs := []models.Source{
-		sources.NewLenta(),
-		sources.NewRia(),
-		sources.NewTass(),
-		// TODO sources.NewMeduza(), и т.д.
+	sourceConfigs, err := sources.LoadConfig(cfg.SourcesFile)
+	if err != nil {
+		log.Fatalf("error loading sources: %v", err)
 	}
 
-	embedder, err := embedding.NewEmbedder(cfg.EmbedderURL)
+	httpClient, err := sources.NewHTTPClient(sources.HTTPConfig{
+		Timeout:     cfg.HTTPTimeout,
+		UserAgent:   cfg.HTTPUserAgent,
+		ProxyURL:    cfg.HTTPProxyURL,
+		MaxBodySize: cfg.HTTPMaxBodySize,
+	})
 	if err != nil {
-		log.Fatalf("error connect to embed-service: %v", err) // TODO test try
+		log.Fatalf("error creating http client: %v", err)
+	}
+
+	srcs, err := sources.Build(sourceConfigs, httpClient)
+	if err != nil {
+		log.Fatalf("error building sources: %v", err)
 	}
+	log.Printf("Loaded %d enabled sources from %s", len(srcs), cfg.SourcesFile)
 
 	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
 	if err != nil {
@@ -51,10 +62,89 @@ func main() {
 
 	storageFacade := newStorageFacade(pool)
 
-	f := fetch.NewFetcher(srcs, embedder, storageFacade, cfg.MaxWorkers)
+	dialOpts, err := embedding.DialOptions(embedding.ClientTransport{
+		TLS:        cfg.EmbedderTLS,
+		CAFile:     cfg.EmbedderTLSCA,
+		CertFile:   cfg.EmbedderTLSCert,
+		KeyFile:    cfg.EmbedderTLSKey,
+		ServerName: cfg.EmbedderTLSServerName,
+		Token:      cfg.EmbedderToken,
+	})
+	if err != nil {
+		log.Fatalf("error configuring embedder transport: %v", err)
+	}
+
+	embedderOpts := []embedding.Option{
+		embedding.WithDialOptions(dialOpts...),
+		embedding.WithBalancing(embedding.BalancerConfig{
+			Strategy:         cfg.EmbedBalancer,
+			BreakerThreshold: cfg.EmbedBreakerThreshold,
+			BreakerCooldown:  cfg.EmbedBreakerCooldown,
+		}),
+	}
+	if cfg.EmbedCacheEnabled {
+		var store embedding.CacheStore
+		if cfg.EmbedCachePersistent {
+			store = storageFacade
+		}
+		cache := embedding.NewCache(store, cfg.EmbedCacheSize, cfg.EmbedCacheTTL)
+		go cache.StartCleanup(ctx, cfg.EmbedCacheCleanup)
+		embedderOpts = append(embedderOpts, embedding.WithCache(cache))
+	}
+
+	embedder, err := embedding.NewEmbedder(cfg.EmbedderURLs, embedderOpts...)
+	if err != nil {
+		log.Fatalf("error connect to embed-service: %v", err) // TODO test try
+	}
+	go embedder.StartHealthProbe(ctx, cfg.EmbedHealthInterval)
+
+	schedules, err := sources.Schedules(sourceConfigs, cfg.FetchInterval)
+	if err != nil {
+		log.Fatalf("error loading source schedules: %v", err)
+	}
+
+	opts := []fetch.Option{
+		fetch.WithWatermarkOverlap(cfg.WatermarkOverlap),
+		fetch.WithEmbedBatchSize(cfg.EmbedBatchSize),
+		fetch.WithSchedules(schedules),
+		fetch.WithHealthPolicy(fetch.HealthPolicy{
+			Threshold: cfg.QuarantineThreshold,
+			BaseDelay: cfg.QuarantineBase,
+			MaxDelay:  cfg.QuarantineMax,
+		}),
+		fetch.WithBacklogPolicy(fetch.BacklogPolicy{
+			Interval:  cfg.BacklogInterval,
+			BatchSize: cfg.BacklogBatchSize,
+			Lease:     cfg.BacklogLease,
+			BaseDelay: cfg.BacklogBase,
+			MaxDelay:  cfg.BacklogMax,
+		}),
+	}
+	if cfg.ClusterEnabled {
+		clusterer := cluster.NewClusterer(storageFacade, cfg.ClusterThreshold, cfg.ClusterWindow, cfg.ClusterNeighbours)
+		opts = append(opts, fetch.WithClusterer(clusterer))
+	}
+	if cfg.ChunkEnabled {
+		opts = append(opts, fetch.WithChunker(chunk.NewChunker(cfg.ChunkMaxTokens, cfg.ChunkOverlap)))
+	}
+	if cfg.ExtractFullText {
+		rules, err := sources.ExtractRules(sourceConfigs)
+		if err != nil {
+			log.Fatalf("error loading extraction rules: %v", err)
+		}
+		extractor := extract.NewExtractor(httpClient, rules, cfg.ExtractMaxBytes)
+		opts = append(opts, fetch.WithExtractor(extractor))
+	}
+
+	f := fetch.NewFetcher(srcs, embedder, storageFacade, cfg.MaxWorkers, opts...)
+
+	go func() {
+		log.Printf("Starting embedding backlog worker with interval %s...", cfg.BacklogInterval)
+		f.StartBacklog(ctx)
+	}()
 
 	go func() {
-		log.Printf("Starting Fetcher with interval %s...", cfg.FetchInterval)
+		log.Printf("Starting Fetcher with default interval %s...", cfg.FetchInterval)
 		err := f.Start(ctx, cfg.FetchInterval)
 		if err != nil {
 			log.Fatalf("Fetcher failed: %v", err)
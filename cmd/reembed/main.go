@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"newstrix/internal/chunk"
+	"newstrix/internal/config"
+	"newstrix/internal/embedding"
+	"newstrix/internal/reembed"
+	"newstrix/internal/storage"
+	"newstrix/internal/storage/postgres"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	cfg := config.Load()
+
+	batchSize := flag.Int("batch", cfg.ReembedBatchSize, "items per embedding call")
+	reset := flag.Bool("reset", false, "ignore saved progress and start from the first item")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sig
+		log.Println("Received termination signal, progress is saved after the current batch")
+		cancel()
+	}()
+
+	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	storageFacade := newStorageFacade(pool)
+
+	dialOpts, err := embedding.DialOptions(embedding.ClientTransport{
+		TLS:        cfg.EmbedderTLS,
+		CAFile:     cfg.EmbedderTLSCA,
+		CertFile:   cfg.EmbedderTLSCert,
+		KeyFile:    cfg.EmbedderTLSKey,
+		ServerName: cfg.EmbedderTLSServerName,
+		Token:      cfg.EmbedderToken,
+	})
+	if err != nil {
+		log.Fatalf("error configuring embedder transport: %v", err)
+	}
+
+	embedder, err := embedding.NewEmbedder(cfg.EmbedderURLs,
+		embedding.WithDialOptions(dialOpts...),
+		embedding.WithBalancing(embedding.BalancerConfig{
+			Strategy:         cfg.EmbedBalancer,
+			BreakerThreshold: cfg.EmbedBreakerThreshold,
+			BreakerCooldown:  cfg.EmbedBreakerCooldown,
+		}),
+	)
+	if err != nil {
+		log.Fatalf("error connect to embed-service: %v", err)
+	}
+
+	var chunker *chunk.Chunker
+	if cfg.ChunkEnabled {
+		chunker = chunk.NewChunker(cfg.ChunkMaxTokens, cfg.ChunkOverlap)
+	}
+
+	job := reembed.NewJob(storageFacade, embedder, chunker, *batchSize)
+	if *reset {
+		if err := job.Reset(ctx); err != nil {
+			log.Fatalf("error resetting progress: %v", err)
+		}
+	}
+
+	if err := job.Run(ctx); err != nil {
+		log.Fatalf("Re-embedding failed: %v", err)
+	}
+}
+
+func newStorageFacade(pool *pgxpool.Pool) storage.Facade {
+	txManager := postgres.NewTxManager(pool)
+	pgRepository := postgres.NewPgRepository(txManager)
+
+	return storage.NewStorageFacade(txManager, pgRepository)
+}
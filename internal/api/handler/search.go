@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
+	"math"
 	"net/http"
 	"newstrix/internal/search"
 	"strconv"
@@ -17,8 +20,23 @@ func NewSearchHandler(s *search.SearchEngine) *SearchHandler {
 	return &SearchHandler{service: s}
 }
 
-// GET /search/semantic?query=текст&limit=5
+// pageResponse wraps one page of hits. PageCount is the number of items on
+// this page only: no total is computed, as that would take a second query
+// over every match. Params echo the search after defaults; Next repeats the
+// request with the cursor of the following page.
+type pageResponse struct {
+	Items      interface{}            `json:"items"`
+	PageCount  int                    `json:"page_count"`
+	Params     search.EffectiveParams `json:"params"`
+	ScoreType  string                 `json:"score_type,omitempty"`
+	TookMs     int64                  `json:"took_ms"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Next       string                 `json:"next,omitempty"`
+}
+
+// GET /search/semantic?query=текст&limit=5&collapse=true&mode=chunks|hybrid&min_score=0.5&cursor=...
 func (h *SearchHandler) SemanticSearch(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	query := r.URL.Query().Get("query")
 	var limit int = 20
 	if l := r.URL.Query().Get("limit"); l != "" {
@@ -30,16 +48,47 @@ func (h *SearchHandler) SemanticSearch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	results, err := h.service.SearchBySemanticQuery(r.Context(), query, limit)
+	collapse, err := parseCollapse(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid collapse parameter", http.StatusBadRequest)
+		return
+	}
+
+	mode, err := search.ParseMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(w, "Invalid mode parameter", http.StatusBadRequest)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, results)
+	minScore, err := parseMinScore(r)
+	if err != nil {
+		http.Error(w, "Invalid min_score parameter", http.StatusBadRequest)
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	if collapse {
+		page, err := h.service.SearchBySemanticQueryCollapsed(r.Context(), query, limit, mode, minScore, cursor)
+		if err != nil {
+			searchError(w, err)
+			return
+		}
+		respondPage(w, r, start, page.Items, len(page.Items), page.PageInfo)
+		return
+	}
+
+	page, err := h.service.SearchBySemanticQuery(r.Context(), query, limit, mode, minScore, cursor)
+	if err != nil {
+		searchError(w, err)
+		return
+	}
+
+	respondPage(w, r, start, page.Items, len(page.Items), page.PageInfo)
 }
 
 func (h *SearchHandler) SearchByFilters(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	request := search.QueryOption{}
 
 	if query := r.URL.Query().Get("query"); query != "" {
@@ -80,13 +129,43 @@ func (h *SearchHandler) SearchByFilters(w http.ResponseWriter, r *http.Request)
 		request.Limit = 0
 	}
 
-	results, err := h.service.SearchAdvanced(r.Context(), request)
+	collapse, err := parseCollapse(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid collapse parameter", http.StatusBadRequest)
+		return
+	}
+
+	request.Mode, err = search.ParseMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(w, "Invalid mode parameter", http.StatusBadRequest)
+		return
+	}
+
+	request.MinScore, err = parseMinScore(r)
+	if err != nil {
+		http.Error(w, "Invalid min_score parameter", http.StatusBadRequest)
+		return
+	}
+
+	request.Cursor = r.URL.Query().Get("cursor")
+
+	if collapse {
+		page, err := h.service.SearchAdvancedCollapsed(r.Context(), request)
+		if err != nil {
+			searchError(w, err)
+			return
+		}
+		respondPage(w, r, start, page.Items, len(page.Items), page.PageInfo)
+		return
+	}
+
+	page, err := h.service.SearchAdvanced(r.Context(), request)
+	if err != nil {
+		searchError(w, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, results)
+	respondPage(w, r, start, page.Items, len(page.Items), page.PageInfo)
 }
 
 func (h *SearchHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -110,6 +189,78 @@ func (h *SearchHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, item)
 }
 
+// GET /search/{id}/revisions
+func (h *SearchHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		http.Error(w, "id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.service.GetRevisions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(revisions) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, revisions)
+}
+
+// parseCollapse reads the collapse parameter which folds hits of the same
+// story cluster into one.
+func parseCollapse(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("collapse")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseMinScore reads the min_score parameter below which vector hits are
+// dropped; it is nil when absent.
+func parseMinScore(r *http.Request) (*float64, error) {
+	value := r.URL.Query().Get("min_score")
+	if value == "" {
+		return nil, nil
+	}
+	minScore, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(minScore) || math.IsInf(minScore, 0) {
+		return nil, fmt.Errorf("invalid min_score %q", value)
+	}
+	return &minScore, nil
+}
+
+// respondPage writes count items in a pageResponse, timed from start.
+func respondPage(w http.ResponseWriter, r *http.Request, start time.Time, items interface{}, count int, info search.PageInfo) {
+	resp := pageResponse{
+		Items:      items,
+		PageCount:  count,
+		Params:     info.Params,
+		ScoreType:  info.ScoreType,
+		TookMs:     time.Since(start).Milliseconds(),
+		NextCursor: info.Next,
+	}
+	if info.Next != "" {
+		query := r.URL.Query()
+		query.Set("cursor", info.Next)
+		resp.Next = r.URL.Path + "?" + query.Encode()
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func searchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, search.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
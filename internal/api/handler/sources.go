@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"newstrix/internal/models"
+	"time"
+)
+
+type SourceHealthRepository interface {
+	ListSourceHealth(ctx context.Context) ([]models.SourceHealth, error)
+}
+
+type SourceHandler struct {
+	repository SourceHealthRepository
+}
+
+func NewSourceHandler(r SourceHealthRepository) *SourceHandler {
+	return &SourceHandler{repository: r}
+}
+
+type sourceHealthResponse struct {
+	models.SourceHealth
+	Status string `json:"status"`
+}
+
+// GET /sources/health
+func (h *SourceHandler) Health(w http.ResponseWriter, r *http.Request) {
+	list, err := h.repository.ListSourceHealth(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	response := make([]sourceHealthResponse, 0, len(list))
+	for _, health := range list {
+		response = append(response, sourceHealthResponse{
+			SourceHealth: health,
+			Status:       health.Status(now),
+		})
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
@@ -12,7 +12,7 @@ type Router struct {
 	r *chi.Mux
 }
 
-func SetupRouter(engine *search.SearchEngine) *Router {
+func SetupRouter(engine *search.SearchEngine, sources handler.SourceHealthRepository) *Router {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -28,6 +28,12 @@ func SetupRouter(engine *search.SearchEngine) *Router {
 		r.Get("/semantic", sh.SemanticSearch)
 		r.Get("/", sh.SearchByFilters)
 		r.Get("/{id}", sh.GetByID)
+		r.Get("/{id}/revisions", sh.GetRevisions)
+	})
+
+	r.Route("/sources", func(r chi.Router) {
+		srh := handler.NewSourceHandler(sources)
+		r.Get("/health", srh.Health)
 	})
 
 	return &Router{r: r}
@@ -0,0 +1,177 @@
+package chunk
+
+import (
+	"newstrix/internal/models"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultMaxTokens = 200
+	DefaultOverlap   = 40
+)
+
+// Chunker splits article bodies into passages of at most MaxTokens tokens,
+// counted as words. Passages end on sentence boundaries and, when they are at
+// least half full, on paragraph boundaries. Consecutive passages share up to
+// Overlap tokens of trailing sentences so that a statement cut by a boundary
+// is still found whole in one of them.
+type Chunker struct {
+	maxTokens int
+	overlap   int
+}
+
+func NewChunker(maxTokens, overlap int) *Chunker {
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	if overlap < 0 || overlap >= maxTokens {
+		overlap = DefaultOverlap
+		if overlap >= maxTokens {
+			overlap = maxTokens / 5
+		}
+	}
+	return &Chunker{maxTokens: maxTokens, overlap: overlap}
+}
+
+// Chunks returns the passages of the item's full text, or of its description
+// when no full text was extracted.
+func (c *Chunker) Chunks(item models.NewsItem) []models.NewsChunk {
+	body := item.FullText
+	if body == "" {
+		body = item.Description
+	}
+
+	var chunks []models.NewsChunk
+	for i, text := range c.Split(body) {
+		chunks = append(chunks, models.NewsChunk{NewsID: item.Guid, Index: i, Text: text})
+	}
+	return chunks
+}
+
+type sentence struct {
+	text           string
+	tokens         int
+	paragraphStart bool
+}
+
+func (c *Chunker) Split(text string) []string {
+	var chunks []string
+	var current []sentence
+	tokens := 0
+	// fresh is set once current holds a sentence that is not an overlap from
+	// the previous chunk.
+	fresh := false
+
+	flush := func() {
+		chunks = append(chunks, joinSentences(current))
+		current, tokens = c.tail(current)
+		fresh = false
+	}
+
+	for _, s := range c.sentences(text) {
+		if fresh && (tokens+s.tokens > c.maxTokens || (s.paragraphStart && tokens >= c.maxTokens/2)) {
+			flush()
+		}
+		// Drop overlap that would not leave room for the next sentence.
+		for len(current) > 0 && tokens+s.tokens > c.maxTokens {
+			tokens -= current[0].tokens
+			current = current[1:]
+		}
+		current = append(current, s)
+		tokens += s.tokens
+		fresh = true
+	}
+	if fresh {
+		chunks = append(chunks, joinSentences(current))
+	}
+
+	return chunks
+}
+
+// tail returns the trailing sentences of a flushed chunk to repeat at the
+// start of the next one.
+func (c *Chunker) tail(sentences []sentence) ([]sentence, int) {
+	tokens := 0
+	start := len(sentences)
+	for start > 0 && tokens+sentences[start-1].tokens <= c.overlap {
+		start--
+		tokens += sentences[start].tokens
+	}
+	tail := append([]sentence(nil), sentences[start:]...)
+	if len(tail) > 0 {
+		tail[0].paragraphStart = false
+	}
+	return tail, tokens
+}
+
+// sentences splits text into sentences, breaking sentences longer than
+// maxTokens into word windows.
+func (c *Chunker) sentences(text string) []sentence {
+	var result []sentence
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		first := true
+		for _, s := range splitSentences(paragraph) {
+			words := strings.Fields(s)
+			for len(words) > 0 {
+				n := min(len(words), c.maxTokens)
+				result = append(result, sentence{
+					text:           strings.Join(words[:n], " "),
+					tokens:         n,
+					paragraphStart: first,
+				})
+				words = words[n:]
+				first = false
+			}
+		}
+	}
+	return result
+}
+
+// splitSentences breaks a paragraph after sentence-ending punctuation that is
+// followed by whitespace and an upper-case letter, digit or opening quote.
+func splitSentences(paragraph string) []string {
+	runes := []rune(strings.TrimSpace(paragraph))
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune(".!?…", runes[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && strings.ContainsRune(".!?…\"»)", runes[j]) {
+			j++
+		}
+		k := j
+		for k < len(runes) && unicode.IsSpace(runes[k]) {
+			k++
+		}
+		if k == j || k == len(runes) {
+			continue
+		}
+		if next := runes[k]; unicode.IsUpper(next) || unicode.IsDigit(next) || strings.ContainsRune("\"«(—-", next) {
+			sentences = append(sentences, string(runes[start:j]))
+			start = k
+			i = k - 1
+		}
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+func joinSentences(sentences []sentence) string {
+	var b strings.Builder
+	for i, s := range sentences {
+		if i > 0 {
+			if s.paragraphStart {
+				b.WriteString("\n\n")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(s.text)
+	}
+	return b.String()
+}
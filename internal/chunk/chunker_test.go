@@ -0,0 +1,138 @@
+package chunk
+
+import (
+	"newstrix/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestNewChunker(t *testing.T) {
+	tests := []struct {
+		name                 string
+		maxTokens, overlap   int
+		wantMax, wantOverlap int
+	}{
+		{name: "defaults", maxTokens: 0, overlap: -1, wantMax: DefaultMaxTokens, wantOverlap: DefaultOverlap},
+		{name: "explicit", maxTokens: 100, overlap: 10, wantMax: 100, wantOverlap: 10},
+		{name: "no overlap", maxTokens: 100, overlap: 0, wantMax: 100, wantOverlap: 0},
+		{name: "overlap too large", maxTokens: 100, overlap: 100, wantMax: 100, wantOverlap: DefaultOverlap},
+		{name: "default overlap too large", maxTokens: 20, overlap: 30, wantMax: 20, wantOverlap: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChunker(tt.maxTokens, tt.overlap)
+			if c.maxTokens != tt.wantMax || c.overlap != tt.wantOverlap {
+				t.Fatalf("NewChunker(%d, %d) = %d, %d, want %d, %d",
+					tt.maxTokens, tt.overlap, c.maxTokens, c.overlap, tt.wantMax, tt.wantOverlap)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxTokens, overlap int
+		text               string
+		want               []string
+	}{
+		{
+			name:      "empty",
+			maxTokens: 10,
+			text:      "",
+			want:      nil,
+		},
+		{
+			name:      "fits one chunk",
+			maxTokens: 10,
+			text:      "One two. Three four.",
+			want:      []string{"One two. Three four."},
+		},
+		{
+			name:      "sentence boundary with overlap",
+			maxTokens: 4,
+			overlap:   2,
+			text:      "A b. C d. E f.",
+			want:      []string{"A b. C d.", "C d. E f."},
+		},
+		{
+			name:      "overlap dropped when no room",
+			maxTokens: 4,
+			overlap:   2,
+			text:      "A b. C d e f.",
+			want:      []string{"A b.", "C d e f."},
+		},
+		{
+			name:      "paragraph boundary when half full",
+			maxTokens: 10,
+			text:      "A b c. D e f.\n\nG h.",
+			want:      []string{"A b c. D e f.", "G h."},
+		},
+		{
+			name:      "paragraphs merged when small",
+			maxTokens: 10,
+			text:      "A b.\n\nC d.",
+			want:      []string{"A b.\n\nC d."},
+		},
+		{
+			name:      "long sentence split into windows",
+			maxTokens: 3,
+			text:      "a b c d e f g",
+			want:      []string{"a b c", "d e f", "g"},
+		},
+		{
+			name:      "no break before lower case",
+			maxTokens: 2,
+			text:      "Т.е. проверка. Дальше.",
+			want:      []string{"Т.е. проверка.", "Дальше."},
+		},
+		{
+			name:      "closing quote stays with sentence",
+			maxTokens: 3,
+			text:      "Он сказал: «Да.» Потом ушёл.",
+			want:      []string{"Он сказал: «Да.»", "Потом ушёл."},
+		},
+		{
+			name:      "whitespace normalized",
+			maxTokens: 10,
+			text:      "  One\ttwo.   Three\nfour.  ",
+			want:      []string{"One two. Three four."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewChunker(tt.maxTokens, tt.overlap).Split(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		item models.NewsItem
+		want []string
+	}{
+		{name: "full text", item: models.NewsItem{Guid: "a", FullText: "A b c. D e f.", Description: "Desc."}, want: []string{"A b c.", "D e f."}},
+		{name: "description", item: models.NewsItem{Guid: "a", Description: "Desc."}, want: []string{"Desc."}},
+		{name: "nothing", item: models.NewsItem{Guid: "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := NewChunker(4, 0).Chunks(tt.item)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("Chunks = %d passages, want %d", len(chunks), len(tt.want))
+			}
+			for i, chunk := range chunks {
+				if chunk.NewsID != tt.item.Guid || chunk.Index != i || chunk.Text != tt.want[i] {
+					t.Fatalf("chunk %d = %+v, want %q", i, chunk, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"context"
+	"math"
+	"newstrix/internal/models"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultThreshold  = 0.85
+	DefaultWindow     = 12 * time.Hour
+	DefaultNeighbours = 10
+)
+
+// Neighbour is a stored item close to the one being clustered.
+type Neighbour struct {
+	ID          string
+	ClusterID   string
+	PublishedAt time.Time
+	Similarity  float64
+}
+
+type Repository interface {
+	// NearestNews returns stored items embedded by model and published in
+	// [from, to] ordered by cosine similarity to vector, most similar first.
+	NearestNews(ctx context.Context, model string, vector []float32, from, to time.Time, limit int) ([]Neighbour, error)
+}
+
+// Clusterer assigns items to story clusters: an item joins the cluster of its
+// most similar neighbour published within the window if the cosine similarity
+// reaches the threshold, otherwise it starts a new cluster named after itself.
+type Clusterer struct {
+	repository Repository
+	threshold  float64
+	window     time.Duration
+	neighbours int
+}
+
+func NewClusterer(repository Repository, threshold float64, window time.Duration, neighbours int) *Clusterer {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if neighbours <= 0 {
+		neighbours = DefaultNeighbours
+	}
+
+	return &Clusterer{
+		repository: repository,
+		threshold:  threshold,
+		window:     window,
+		neighbours: neighbours,
+	}
+}
+
+// Assign sets ClusterID on items in place. pending holds items clustered
+// earlier in the same run that are not stored yet, so that stories published
+// by several sources in one run end up together.
+func (c *Clusterer) Assign(ctx context.Context, items []models.NewsItem, pending []models.NewsItem) error {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].PublishedAt.Before(items[order[b]].PublishedAt)
+	})
+
+	assigned := append([]models.NewsItem(nil), pending...)
+
+	for _, i := range order {
+		item := &items[i]
+		if len(item.Vector) == 0 {
+			continue
+		}
+
+		best, err := c.bestStored(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		for _, other := range assigned {
+			if other.ClusterID == "" || other.EmbeddingModel != item.EmbeddingModel || !c.inWindow(item.PublishedAt, other.PublishedAt) {
+				continue
+			}
+			if sim := Cosine(item.Vector, other.Vector); sim > best.Similarity {
+				best = Neighbour{ID: other.Guid, ClusterID: other.ClusterID, PublishedAt: other.PublishedAt, Similarity: sim}
+			}
+		}
+
+		if best.ClusterID != "" && best.Similarity >= c.threshold {
+			item.ClusterID = best.ClusterID
+		} else {
+			item.ClusterID = item.Guid
+		}
+		assigned = append(assigned, *item)
+	}
+
+	return nil
+}
+
+func (c *Clusterer) bestStored(ctx context.Context, item *models.NewsItem) (Neighbour, error) {
+	neighbours, err := c.repository.NearestNews(ctx, item.EmbeddingModel, item.Vector, item.PublishedAt.Add(-c.window), item.PublishedAt.Add(c.window), c.neighbours)
+	if err != nil {
+		return Neighbour{}, err
+	}
+
+	for _, n := range neighbours {
+		if n.ID != item.Guid {
+			return n, nil
+		}
+	}
+	return Neighbour{}, nil
+}
+
+func (c *Clusterer) inWindow(a, b time.Time) bool {
+	d := a.Sub(b)
+	if d < 0 {
+		d = -d
+	}
+	return d <= c.window
+}
+
+// Cosine returns the cosine similarity of two vectors of equal length.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,227 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	BalanceRoundRobin  = "round_robin"
+	BalanceLeastLoaded = "least_loaded"
+)
+
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+
+	probeTimeout = 5 * time.Second
+)
+
+// ErrNoBackends is returned without a call when the breakers of all backends
+// are open.
+var ErrNoBackends = status.Error(codes.Unavailable, "all embedding backends are unavailable")
+
+// BalancerConfig configures how calls are spread over the embedder backends.
+type BalancerConfig struct {
+	Strategy         string
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// BackendStats describes the state of one backend.
+type BackendStats struct {
+	Addr     string `json:"addr"`
+	State    string `json:"state"`
+	InFlight int64  `json:"in_flight"`
+}
+
+type backend struct {
+	addr     string
+	client   *EmbedClient
+	breaker  *Breaker
+	inFlight atomic.Int64
+}
+
+// Balancer spreads calls over several embedder services. A call that fails
+// because its backend is down is sent to the next one; backends that keep
+// failing are skipped until their breaker lets a call through again.
+type Balancer struct {
+	backends []*backend
+	strategy string
+	next     atomic.Uint64
+}
+
+func NewBalancer(addrs []string, cfg BalancerConfig, opts ...grpc.DialOption) (*Balancer, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no embedder addresses configured")
+	}
+	switch cfg.Strategy {
+	case "":
+		cfg.Strategy = BalanceRoundRobin
+	case BalanceRoundRobin, BalanceLeastLoaded:
+	default:
+		return nil, fmt.Errorf("unknown balancing strategy %q", cfg.Strategy)
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	b := &Balancer{strategy: cfg.Strategy}
+	for _, addr := range addrs {
+		cli, err := NewEmbedClient(addr, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("embedder %s: %w", addr, err)
+		}
+		b.backends = append(b.backends, &backend{
+			addr:    addr,
+			client:  cli,
+			breaker: NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		})
+	}
+	return b, nil
+}
+
+func (b *Balancer) Embed(ctx context.Context, text string) ([]float32, error) {
+	var vector []float32
+	err := b.do(ctx, func(cli *EmbedClient) error {
+		var err error
+		vector, err = cli.Embed(ctx, text)
+		return err
+	})
+	return vector, err
+}
+
+func (b *Balancer) EmbedBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	var results []BatchResult
+	err := b.do(ctx, func(cli *EmbedClient) error {
+		var err error
+		results, err = cli.EmbedBatch(ctx, items)
+		return err
+	})
+	return results, err
+}
+
+func (b *Balancer) Info(ctx context.Context) (ModelInfo, error) {
+	var info ModelInfo
+	err := b.do(ctx, func(cli *EmbedClient) error {
+		var err error
+		info, err = cli.Info(ctx)
+		return err
+	})
+	return info, err
+}
+
+// do runs call against the backends in balancing order until one answers.
+// Only transient failures and misconfigured backends move on to the next
+// backend.
+func (b *Balancer) do(ctx context.Context, call func(*EmbedClient) error) error {
+	var lastErr error
+	for _, be := range b.order() {
+		if !be.breaker.Allow() {
+			continue
+		}
+
+		be.inFlight.Add(1)
+		err := call(be.client)
+		be.inFlight.Add(-1)
+
+		if err == nil || (!IsRetryable(err) && !isBackendFailure(err)) {
+			be.breaker.Success()
+			return err
+		}
+
+		if isBackendFailure(err) {
+			b.failure(be, err)
+		} else {
+			be.breaker.Success()
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+
+	if lastErr != nil {
+		return lastErr
+	}
+	return ErrNoBackends
+}
+
+// order returns the backends in the order a call should try them.
+func (b *Balancer) order() []*backend {
+	n := len(b.backends)
+	start := int(b.next.Add(1) % uint64(n))
+
+	ordered := make([]*backend, 0, n)
+	for i := 0; i < n; i++ {
+		ordered = append(ordered, b.backends[(start+i)%n])
+	}
+	if b.strategy == BalanceLeastLoaded {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].inFlight.Load() < ordered[j].inFlight.Load()
+		})
+	}
+	return ordered
+}
+
+func (b *Balancer) failure(be *backend, err error) {
+	before := be.breaker.State()
+	be.breaker.Failure()
+	if before != BreakerOpen && be.breaker.State() == BreakerOpen {
+		log.Printf("Embedder %s is failing, circuit opened: %v", be.addr, err)
+	}
+}
+
+// Probe asks the health service of every backend whose breaker is not closed
+// and closes the breaker once the backend reports serving again.
+func (b *Balancer) Probe(ctx context.Context) {
+	for _, be := range b.backends {
+		if be.breaker.State() == BreakerClosed {
+			continue
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := be.client.Health(probeCtx)
+		cancel()
+
+		if err != nil {
+			b.failure(be, err)
+			continue
+		}
+		be.breaker.Success()
+		log.Printf("Embedder %s recovered, circuit closed", be.addr)
+	}
+}
+
+func (b *Balancer) Stats() []BackendStats {
+	stats := make([]BackendStats, 0, len(b.backends))
+	for _, be := range b.backends {
+		stats = append(stats, BackendStats{
+			Addr:     be.addr,
+			State:    be.breaker.State().String(),
+			InFlight: be.inFlight.Load(),
+		})
+	}
+	return stats
+}
+
+// isBackendFailure reports whether err means the backend itself is down, too
+// slow or misconfigured, as opposed to it rejecting the request.
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package embedding
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestBalancer returns a round-robin balancer over n backends without
+// connections whose first call starts at the first backend.
+func newTestBalancer(n int) *Balancer {
+	b := &Balancer{strategy: BalanceRoundRobin}
+	for i := 0; i < n; i++ {
+		b.backends = append(b.backends, &backend{
+			addr:    string(rune('a' + i)),
+			client:  &EmbedClient{},
+			breaker: NewBreaker(1, time.Hour),
+		})
+	}
+	b.next.Store(uint64(n - 1))
+	return b
+}
+
+func TestBalancerFailover(t *testing.T) {
+	tests := []struct {
+		name string
+		// errs are the answers of the backends in order; backends not
+		// listed answer successfully.
+		errs       []error
+		wantCalls  []string
+		wantCode   codes.Code
+		wantStates []BreakerState
+	}{
+		{
+			name:       "first answers",
+			wantCalls:  []string{"a"},
+			wantCode:   codes.OK,
+			wantStates: []BreakerState{BreakerClosed, BreakerClosed},
+		},
+		{
+			name:       "down backend is skipped",
+			errs:       []error{status.Error(codes.Unavailable, "down")},
+			wantCalls:  []string{"a", "b"},
+			wantCode:   codes.OK,
+			wantStates: []BreakerState{BreakerOpen, BreakerClosed},
+		},
+		{
+			name:       "slow backend is skipped",
+			errs:       []error{status.Error(codes.DeadlineExceeded, "slow")},
+			wantCalls:  []string{"a", "b"},
+			wantCode:   codes.OK,
+			wantStates: []BreakerState{BreakerOpen, BreakerClosed},
+		},
+		{
+			name:       "misconfigured backend is skipped",
+			errs:       []error{status.Error(codes.FailedPrecondition, "model not found")},
+			wantCalls:  []string{"a", "b"},
+			wantCode:   codes.OK,
+			wantStates: []BreakerState{BreakerOpen, BreakerClosed},
+		},
+		{
+			name:       "overloaded backend stays closed",
+			errs:       []error{status.Error(codes.ResourceExhausted, "busy")},
+			wantCalls:  []string{"a", "b"},
+			wantCode:   codes.OK,
+			wantStates: []BreakerState{BreakerClosed, BreakerClosed},
+		},
+		{
+			name:       "rejected input is not retried",
+			errs:       []error{status.Error(codes.InvalidArgument, "bad input")},
+			wantCalls:  []string{"a"},
+			wantCode:   codes.InvalidArgument,
+			wantStates: []BreakerState{BreakerClosed, BreakerClosed},
+		},
+		{
+			name:       "all down",
+			errs:       []error{status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down")},
+			wantCalls:  []string{"a", "b"},
+			wantCode:   codes.Unavailable,
+			wantStates: []BreakerState{BreakerOpen, BreakerOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBalancer(2)
+			var calls []string
+			err := b.do(context.Background(), func(cli *EmbedClient) error {
+				for i, be := range b.backends {
+					if be.client == cli {
+						calls = append(calls, be.addr)
+						if i < len(tt.errs) {
+							return tt.errs[i]
+						}
+					}
+				}
+				return nil
+			})
+
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("do = %v, want %s", err, tt.wantCode)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Fatalf("called %v, want %v", calls, tt.wantCalls)
+			}
+			for i, be := range b.backends {
+				if got := be.breaker.State(); got != tt.wantStates[i] {
+					t.Fatalf("backend %s breaker %s, want %s", be.addr, got, tt.wantStates[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBalancerNoBackends(t *testing.T) {
+	b := newTestBalancer(2)
+	for _, be := range b.backends {
+		be.breaker.Failure()
+	}
+
+	called := false
+	err := b.do(context.Background(), func(*EmbedClient) error {
+		called = true
+		return nil
+	})
+	if err != ErrNoBackends || called {
+		t.Fatalf("do = %v, called %v, want ErrNoBackends without a call", err, called)
+	}
+	if IsRetryable(err) {
+		t.Fatal("ErrNoBackends must not be retried")
+	}
+}
+
+func TestBalancerOrder(t *testing.T) {
+	b := newTestBalancer(3)
+	b.strategy = BalanceLeastLoaded
+	b.backends[0].inFlight.Store(2)
+	b.backends[2].inFlight.Store(1)
+
+	var order []string
+	for _, be := range b.order() {
+		order = append(order, be.addr)
+	}
+	if want := []string{"b", "c", "a"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,47 @@
+package embedding
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		cooldown  time.Duration
+		// steps are "allow" and "deny", which check Allow, and "success"
+		// and "failure", which record a call.
+		steps []string
+		want  BreakerState
+	}{
+		{name: "closed", threshold: 3, cooldown: time.Hour, steps: []string{"failure", "failure", "allow"}, want: BreakerClosed},
+		{name: "opens at threshold", threshold: 2, cooldown: time.Hour, steps: []string{"failure", "failure", "deny", "deny"}, want: BreakerOpen},
+		{name: "success resets failures", threshold: 2, cooldown: time.Hour, steps: []string{"failure", "success", "failure", "allow"}, want: BreakerClosed},
+		{name: "zero threshold", threshold: 0, cooldown: time.Hour, steps: []string{"failure", "deny"}, want: BreakerOpen},
+		{name: "single trial after cooldown", threshold: 1, steps: []string{"failure", "allow", "deny"}, want: BreakerHalfOpen},
+		{name: "trial success closes", threshold: 1, steps: []string{"failure", "allow", "success", "allow", "allow"}, want: BreakerClosed},
+		{name: "trial failure reopens", threshold: 3, steps: []string{"failure", "failure", "failure", "allow", "failure"}, want: BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(tt.threshold, tt.cooldown)
+			for i, step := range tt.steps {
+				switch step {
+				case "allow", "deny":
+					if got := b.Allow(); got != (step == "allow") {
+						t.Fatalf("step %d: Allow = %v in state %s", i, got, b.State())
+					}
+				case "success":
+					b.Success()
+				case "failure":
+					b.Failure()
+				}
+			}
+			if got := b.State(); got != tt.want {
+				t.Fatalf("State = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStore is the persistent tier of Cache, shared between processes.
+type CacheStore interface {
+	// GetEmbeddings returns the vectors stored for model under the given text
+	// hashes, ignoring entries written before since.
+	GetEmbeddings(ctx context.Context, model string, hashes []string, since time.Time) (map[string][]float32, error)
+	PutEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error
+	// DeleteEmbeddings removes the entries written before the given time and
+	// returns how many were removed.
+	DeleteEmbeddings(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CacheStats counts lookups since the cache was created. StoreHits are
+// lookups that missed memory but were found in the store.
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	StoreHits int64 `json:"store_hits"`
+	Misses    int64 `json:"misses"`
+	Size      int   `json:"size"`
+}
+
+// Cache keeps vectors by model name and normalized text hash in an in-process
+// LRU, backed by an optional CacheStore. Entries older than ttl are treated as
+// missing; a zero ttl keeps them forever.
+type Cache struct {
+	store CacheStore
+	size  int
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	hits      atomic.Int64
+	storeHits atomic.Int64
+	misses    atomic.Int64
+}
+
+type cacheEntry struct {
+	key     string
+	vector  []float32
+	written time.Time
+}
+
+func NewCache(store CacheStore, size int, ttl time.Duration) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		store:   store,
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// TextHash is the cache key of a text: whitespace differences do not change
+// the embedding, so they do not change the key either.
+func TextHash(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the vectors cached for model by text hash. Store errors are
+// logged and treated as misses.
+func (c *Cache) Get(ctx context.Context, model string, hashes []string) map[string][]float32 {
+	found := make(map[string][]float32, len(hashes))
+	var missing []string
+
+	now := time.Now()
+	c.mu.Lock()
+	for _, hash := range hashes {
+		if vector, ok := c.getLocked(cacheKey(model, hash), now); ok {
+			found[hash] = vector
+			c.hits.Add(1)
+			continue
+		}
+		missing = append(missing, hash)
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 && c.store != nil {
+		stored, err := c.store.GetEmbeddings(ctx, model, missing, c.cutoff(now))
+		if err != nil {
+			log.Printf("Failed to read embedding cache: %v", err)
+		}
+		if len(stored) > 0 {
+			c.mu.Lock()
+			for hash, vector := range stored {
+				c.addLocked(cacheKey(model, hash), vector, now)
+				found[hash] = vector
+			}
+			c.mu.Unlock()
+			c.storeHits.Add(int64(len(stored)))
+		}
+		c.misses.Add(int64(len(missing) - len(stored)))
+	} else {
+		c.misses.Add(int64(len(missing)))
+	}
+
+	return found
+}
+
+// Put adds vectors of model by text hash to both tiers.
+func (c *Cache) Put(ctx context.Context, model string, vectors map[string][]float32) {
+	if len(vectors) == 0 {
+		return
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	for hash, vector := range vectors {
+		c.addLocked(cacheKey(model, hash), vector, now)
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.PutEmbeddings(ctx, model, vectors); err != nil {
+			log.Printf("Failed to write embedding cache: %v", err)
+		}
+	}
+}
+
+// StartCleanup deletes expired entries from the store right away and then
+// every interval until ctx is done. Reads already skip them; this only keeps
+// the table from growing. Without a store or a ttl there is nothing to do.
+func (c *Cache) StartCleanup(ctx context.Context, interval time.Duration) {
+	if c.store == nil || c.ttl <= 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := c.store.DeleteEmbeddings(ctx, c.cutoff(time.Now()))
+		if err != nil {
+			log.Printf("Failed to clean up embedding cache: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired embedding cache entries", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		StoreHits: c.storeHits.Load(),
+		Misses:    c.misses.Load(),
+		Size:      size,
+	}
+}
+
+func cacheKey(model, hash string) string {
+	return model + "\x00" + hash
+}
+
+func (c *Cache) cutoff(now time.Time) time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-c.ttl)
+}
+
+func (c *Cache) getLocked(key string, now time.Time) ([]float32, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if entry.written.Before(c.cutoff(now)) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.vector, true
+}
+
+func (c *Cache) addLocked(key string, vector []float32, now time.Time) {
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.vector = vector
+		entry.written = now
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector, written: now})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -3,23 +3,52 @@ package embedding
 import (
 	"context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"newstrix/internal/embedding/proto" // путь до автогенерированного кода
 )
 
 type EmbedClient struct {
 	client pb.EmbedderClient
+	health healthpb.HealthClient
 }
 
-func NewEmbedClient(addr string) (*EmbedClient, error) {
-	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+// NewEmbedClient connects to the embedder at addr. Without options the
+// connection is plaintext; see DialOptions.
+func NewEmbedClient(addr string, opts ...grpc.DialOption) (*EmbedClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.Dial(addr, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &EmbedClient{
 		client: pb.NewEmbedderClient(conn),
+		health: healthpb.NewHealthClient(conn),
 	}, nil
 }
 
+// ModelInfo identifies the model behind the embedder service.
+type ModelInfo struct {
+	Model     string
+	Dimension int
+}
+
+// BatchItem is a text to embed, identified by the caller's id.
+type BatchItem struct {
+	ID   string
+	Text string
+}
+
+// BatchResult holds the vector for one BatchItem, or the error that prevented
+// embedding it. Err is a gRPC status error.
+type BatchResult struct {
+	ID     string
+	Vector []float32
+	Err    error
+}
+
 func (ec *EmbedClient) Embed(ctx context.Context, text string) ([]float32, error) {
 	resp, err := ec.client.Embed(ctx, &pb.EmbedRequest{Text: text})
 	if err != nil {
@@ -27,3 +56,49 @@ func (ec *EmbedClient) Embed(ctx context.Context, text string) ([]float32, error
 	}
 	return resp.Vector, nil
 }
+
+func (ec *EmbedClient) EmbedBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	req := &pb.EmbedBatchRequest{Items: make([]*pb.EmbedBatchItem, 0, len(items))}
+	for _, item := range items {
+		req.Items = append(req.Items, &pb.EmbedBatchItem{Id: item.ID, Text: item.Text})
+	}
+
+	resp, err := ec.client.EmbedBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		result := BatchResult{ID: r.Id, Vector: r.Vector}
+		if r.Error != "" {
+			code := codes.Code(r.Code)
+			if code == codes.OK {
+				code = codes.Unknown
+			}
+			result.Err = status.Error(code, r.Error)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Health asks the embedder service whether its model is usable.
+func (ec *EmbedClient) Health(ctx context.Context) error {
+	resp, err := ec.health.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.Embedder_ServiceDesc.ServiceName})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unavailable, "embedder is %s", resp.Status)
+	}
+	return nil
+}
+
+func (ec *EmbedClient) Info(ctx context.Context) (ModelInfo, error) {
+	resp, err := ec.client.Info(ctx, &pb.InfoRequest{})
+	if err != nil {
+		return ModelInfo{}, err
+	}
+	return ModelInfo{Model: resp.Model, Dimension: int(resp.Dimension)}, nil
+}
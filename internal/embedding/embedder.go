@@ -2,25 +2,237 @@ package embedding
 
 import (
 	"context"
+	"google.golang.org/grpc"
+	"log"
+	"sync"
 	"time"
 )
 
+const (
+	vectorizeTimeout = 5 * time.Second
+	// batchTimeout bounds a whole VectorizeBatch call; one model call for a
+	// batch takes longer than for a single text.
+	batchTimeout = 60 * time.Second
+	// infoTTL is how long the model reported by the service is trusted before
+	// it is asked again, so that a model switch is noticed without restarts.
+	infoTTL = time.Minute
+)
+
 type Embedder struct {
-	client *EmbedClient
+	client      *Balancer
+	cache       *Cache
+	balancing   BalancerConfig
+	dialOptions []grpc.DialOption
+	priority    Priority
+
+	infoMu      sync.Mutex
+	info        ModelInfo
+	infoFetched time.Time
+}
+
+type Option func(*Embedder)
+
+// WithCache serves repeated texts from c instead of the embedder service.
+func WithCache(c *Cache) Option {
+	return func(e *Embedder) {
+		e.cache = c
+	}
+}
+
+// WithBalancing sets how calls are spread over several embedder addresses
+// and when a failing one is skipped.
+func WithBalancing(cfg BalancerConfig) Option {
+	return func(e *Embedder) {
+		e.balancing = cfg
+	}
 }
 
-func NewEmbedder(addr string) (*Embedder, error) {
-	cli, err := NewEmbedClient(addr)
+// WithDialOptions is used when connecting to the embedder services, e.g. for
+// TLS and token authentication.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(e *Embedder) {
+		e.dialOptions = append(e.dialOptions, opts...)
+	}
+}
+
+// WithPriority marks all calls of the embedder with priority. The embedder
+// service serves interactive calls before queued bulk work.
+func WithPriority(priority Priority) Option {
+	return func(e *Embedder) {
+		e.priority = priority
+	}
+}
+
+func NewEmbedder(addrs []string, opts ...Option) (*Embedder, error) {
+	e := &Embedder{}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	cli, err := NewBalancer(addrs, e.balancing, e.dialOptions...)
 	if err != nil {
 		return nil, err
 	}
+	e.client = cli
+	return e, nil
+}
+
+// StartHealthProbe probes failing backends every interval until ctx is done,
+// so that a recovered backend is used again before its breaker cooldown ends.
+func (e *Embedder) StartHealthProbe(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.client.Probe(ctx)
+		}
+	}
+}
+
+// BackendStats returns the state of every embedder backend.
+func (e *Embedder) BackendStats() []BackendStats {
+	return e.client.Stats()
+}
+
+// CacheStats returns the cache counters, or nil when caching is disabled.
+func (e *Embedder) CacheStats() *CacheStats {
+	if e.cache == nil {
+		return nil
+	}
+	stats := e.cache.Stats()
+	return &stats
+}
+
+// Info returns the model currently served by the embedder service.
+func (e *Embedder) Info(ctx context.Context) (ModelInfo, error) {
+	e.infoMu.Lock()
+	defer e.infoMu.Unlock()
+
+	if e.info.Model != "" && time.Since(e.infoFetched) < infoTTL {
+		return e.info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vectorizeTimeout)
+	defer cancel()
+
+	info, err := e.client.Info(ctx)
+	if err != nil {
+		return ModelInfo{}, err
+	}
+	if e.info.Model != "" && e.info.Model != info.Model {
+		log.Printf("Embedding model changed from %s to %s", e.info.Model, info.Model)
+	}
 
-	return &Embedder{client: cli}, nil
+	e.info = info
+	e.infoFetched = time.Now()
+	return info, nil
+}
+
+// Model returns the name of the model currently served by the embedder
+// service.
+func (e *Embedder) Model(ctx context.Context) (string, error) {
+	info, err := e.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	return info.Model, nil
+}
+
+// cacheModel returns the model to cache vectors under, or an empty string when
+// the cache is disabled or the model is unknown.
+func (e *Embedder) cacheModel(ctx context.Context) string {
+	if e.cache == nil {
+		return ""
+	}
+	info, err := e.Info(ctx)
+	if err != nil {
+		log.Printf("Skipping embedding cache, model unknown: %v", err)
+		return ""
+	}
+	return info.Model
 }
 
 func (e *Embedder) Vectorize(ctx context.Context, text string) ([]float32, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	model := e.cacheModel(ctx)
+
+	var hash string
+	if model != "" {
+		hash = TextHash(text)
+		if vector, ok := e.cache.Get(ctx, model, []string{hash})[hash]; ok {
+			return vector, nil
+		}
+	}
+
+	callCtx, cancel := context.WithTimeout(withPriority(ctx, e.priority), vectorizeTimeout)
+	defer cancel()
+
+	vector, err := e.client.Embed(callCtx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if model != "" {
+		e.cache.Put(ctx, model, map[string][]float32{hash: vector})
+	}
+	return vector, nil
+}
+
+// VectorizeBatch embeds all items in one call. The returned error is set when
+// the call as a whole failed; errors for single items are reported in their
+// results. Cached items are answered without a call.
+func (e *Embedder) VectorizeBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	model := e.cacheModel(ctx)
+	if model == "" {
+		return e.embedBatch(ctx, items)
+	}
+
+	hashes := make([]string, len(items))
+	for i, item := range items {
+		hashes[i] = TextHash(item.Text)
+	}
+	cached := e.cache.Get(ctx, model, hashes)
+
+	results := make([]BatchResult, 0, len(items))
+	var missing []BatchItem
+	hashByID := make(map[string]string, len(items))
+	for i, item := range items {
+		if vector, ok := cached[hashes[i]]; ok {
+			results = append(results, BatchResult{ID: item.ID, Vector: vector})
+			continue
+		}
+		missing = append(missing, item)
+		hashByID[item.ID] = hashes[i]
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	embedded, err := e.embedBatch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make(map[string][]float32, len(embedded))
+	for _, result := range embedded {
+		if result.Err == nil {
+			if hash, ok := hashByID[result.ID]; ok {
+				fresh[hash] = result.Vector
+			}
+		}
+		results = append(results, result)
+	}
+	e.cache.Put(ctx, model, fresh)
+
+	return results, nil
+}
+
+func (e *Embedder) embedBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	ctx, cancel := context.WithTimeout(withPriority(ctx, e.priority), batchTimeout)
 	defer cancel()
 
-	return e.client.Embed(ctx, text)
+	return e.client.EmbedBatch(ctx, items)
 }
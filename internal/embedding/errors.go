@@ -0,0 +1,129 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrEmptyEmbedding is returned when a provider answers without a vector.
+var ErrEmptyEmbedding = errors.New("provider returned no embedding")
+
+// HTTPError is a non-2xx answer of an HTTP embedding provider.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("provider returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("provider returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Code classifies a provider error as a gRPC status code.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+
+	var httpErr *HTTPError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, ErrEmptyEmbedding):
+		return codes.Internal
+	case errors.As(err, &httpErr):
+		switch {
+		case isMisconfigured(httpErr):
+			return codes.FailedPrecondition
+		case httpErr.StatusCode == http.StatusTooManyRequests:
+			return codes.ResourceExhausted
+		case httpErr.StatusCode == http.StatusRequestTimeout || httpErr.StatusCode == http.StatusGatewayTimeout:
+			return codes.DeadlineExceeded
+		case httpErr.StatusCode >= 500:
+			return codes.Unavailable
+		case httpErr.StatusCode >= 400:
+			return codes.InvalidArgument
+		}
+		return codes.Internal
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return codes.DeadlineExceeded
+		}
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// isMisconfigured reports whether the provider refused because of its own
+// setup rather than the input: the model is not pulled or the credentials are
+// wrong. Ollama answers 404 for a model it does not have.
+func isMisconfigured(e *HTTPError) bool {
+	switch e.StatusCode {
+	case http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "model") && strings.Contains(message, "not found")
+}
+
+// Status converts a provider error to a gRPC status error. The message only
+// names the failure class; details stay in the server log.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := Code(err)
+	return status.Error(code, statusMessage(code))
+}
+
+func statusMessage(code codes.Code) string {
+	switch code {
+	case codes.Unavailable:
+		return "embedding backend unavailable"
+	case codes.ResourceExhausted:
+		return "embedding backend overloaded"
+	case codes.DeadlineExceeded:
+		return "embedding timed out"
+	case codes.InvalidArgument:
+		return "embedding backend rejected the input"
+	case codes.FailedPrecondition:
+		return "embedding backend misconfigured"
+	case codes.Canceled:
+		return "embedding canceled"
+	default:
+		return "embedding failed"
+	}
+}
+
+// IsRetryable reports whether err is transient: the backend is down,
+// overloaded or slow. ErrNoBackends is not retried: the breakers stay open
+// for their cooldown, so an immediate retry would fail the same way.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrNoBackends) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
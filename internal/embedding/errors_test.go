@@ -0,0 +1,97 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "nil", err: nil, want: codes.OK},
+		{name: "status", err: status.Error(codes.NotFound, "missing"), want: codes.NotFound},
+		{name: "deadline", err: fmt.Errorf("embed: %w", context.DeadlineExceeded), want: codes.DeadlineExceeded},
+		{name: "canceled", err: context.Canceled, want: codes.Canceled},
+		{name: "empty embedding", err: ErrEmptyEmbedding, want: codes.Internal},
+		{name: "too many requests", err: &HTTPError{StatusCode: 429}, want: codes.ResourceExhausted},
+		{name: "request timeout", err: &HTTPError{StatusCode: 408}, want: codes.DeadlineExceeded},
+		{name: "gateway timeout", err: &HTTPError{StatusCode: 504}, want: codes.DeadlineExceeded},
+		{name: "server error", err: &HTTPError{StatusCode: 500}, want: codes.Unavailable},
+		{name: "bad gateway", err: fmt.Errorf("call: %w", &HTTPError{StatusCode: 502}), want: codes.Unavailable},
+		{name: "bad request", err: &HTTPError{StatusCode: 400, Message: "input too long"}, want: codes.InvalidArgument},
+		{name: "model not pulled", err: &HTTPError{StatusCode: 404, Message: "model \"bge-m3\" not found, try pulling it first"}, want: codes.FailedPrecondition},
+		{name: "unauthorized", err: &HTTPError{StatusCode: 401}, want: codes.FailedPrecondition},
+		{name: "forbidden", err: &HTTPError{StatusCode: 403}, want: codes.FailedPrecondition},
+		{name: "unknown model", err: &HTTPError{StatusCode: 400, Message: "Model Not Found"}, want: codes.FailedPrecondition},
+		{name: "redirect", err: &HTTPError{StatusCode: 302}, want: codes.Internal},
+		{name: "network timeout", err: &net.OpError{Op: "read", Err: timeoutError{}}, want: codes.DeadlineExceeded},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: codes.Unavailable},
+		{name: "other", err: errors.New("boom"), want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Fatalf("Code(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if err := Status(nil); err != nil {
+		t.Fatalf("Status(nil) = %v, want nil", err)
+	}
+
+	original := status.Error(codes.NotFound, "missing")
+	if err := Status(original); err != original {
+		t.Fatalf("Status changed a status error: %v", err)
+	}
+
+	err := Status(&HTTPError{StatusCode: 503, Message: "secret upstream detail"})
+	s, _ := status.FromError(err)
+	if s.Code() != codes.Unavailable || s.Message() != "embedding backend unavailable" {
+		t.Fatalf("Status = %s %q, want Unavailable without details", s.Code(), s.Message())
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unavailable", err: Status(&HTTPError{StatusCode: 503}), want: true},
+		{name: "overloaded", err: Status(&HTTPError{StatusCode: 429}), want: true},
+		{name: "timed out", err: Status(context.DeadlineExceeded), want: true},
+		{name: "rejected input", err: Status(&HTTPError{StatusCode: 400}), want: false},
+		{name: "misconfigured", err: Status(&HTTPError{StatusCode: 404}), want: false},
+		{name: "canceled", err: Status(context.Canceled), want: false},
+		{name: "internal", err: Status(ErrEmptyEmbedding), want: false},
+		{name: "not a status", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
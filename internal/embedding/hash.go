@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// HashEmbedder is a deterministic, model-free embedder for tests and offline
+// runs. Every word is hashed into one of Dimension buckets, so texts sharing
+// words get similar vectors.
+type HashEmbedder struct {
+	Dimension int
+}
+
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	return &HashEmbedder{Dimension: dimension}
+}
+
+func (h *HashEmbedder) Embed(_ context.Context, input string) ([]float32, error) {
+	vector := make([]float32, h.Dimension)
+
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(word))
+		sum := hasher.Sum64()
+
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vector[sum%uint64(h.Dimension)] += sign
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+
+	return vector, nil
+}
+
+func (h *HashEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		vector, err := h.Embed(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,62 @@
+package embedding
+
+import (
+	"fmt"
+	"newstrix/internal/models"
+)
+
+// ItemRequests returns the texts to embed for item: the item itself, then
+// each of its chunks.
+func ItemRequests(item models.NewsItem) []BatchItem {
+	requests := make([]BatchItem, 0, 1+len(item.Chunks))
+	requests = append(requests, BatchItem{ID: item.Guid, Text: item.EmbeddingText()})
+	for i := range item.Chunks {
+		requests = append(requests, BatchItem{ID: ChunkRequestID(item.Guid, i), Text: item.Chunks[i].EmbeddingText(item.Title)})
+	}
+	return requests
+}
+
+// ChunkRequestID is the BatchItem id of the chunk at index of item guid.
+func ChunkRequestID(guid string, index int) string {
+	return fmt.Sprintf("%s#chunk-%d", guid, index)
+}
+
+// ApplyVectors sets the vectors of the item and its chunks from results,
+// keyed as by ItemRequests, tags them with the model in info and marks the
+// item ready. It fails if any of them is missing.
+func ApplyVectors(item *models.NewsItem, results map[string]BatchResult, info ModelInfo) error {
+	vector, err := resultVector(results, item.Guid, info)
+	if err != nil {
+		return err
+	}
+	item.Vector = vector
+	item.EmbeddingModel = info.Model
+	item.EmbeddingDim = len(vector)
+	item.EmbeddingStatus = models.EmbeddingReady
+	item.EmbeddingError = ""
+
+	for i := range item.Chunks {
+		vector, err := resultVector(results, ChunkRequestID(item.Guid, i), info)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+		item.Chunks[i].Vector = vector
+		item.Chunks[i].EmbeddingModel = info.Model
+		item.Chunks[i].EmbeddingDim = len(vector)
+	}
+	return nil
+}
+
+func resultVector(results map[string]BatchResult, id string, info ModelInfo) ([]float32, error) {
+	result, ok := results[id]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("missing from batch response")
+	case result.Err != nil:
+		return nil, result.Err
+	case len(result.Vector) != info.Dimension:
+		// The service switched models mid-run.
+		return nil, fmt.Errorf("got %d dimensions, model %s has %d", len(result.Vector), info.Model, info.Dimension)
+	}
+	return result.Vector, nil
+}
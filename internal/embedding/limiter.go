@@ -0,0 +1,213 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"newstrix/internal/embedding/proto"
+)
+
+// Priority orders calls waiting for the embedder. Interactive calls, such as
+// query embeddings for search, are served before queued bulk work.
+type Priority int
+
+const (
+	PriorityBulk Priority = iota
+	PriorityInteractive
+)
+
+const (
+	priorityHeader      = "x-embed-priority"
+	priorityInteractive = "interactive"
+	priorityBulk        = "bulk"
+)
+
+// ErrQueueFull is returned when all slots are busy and the wait queue is
+// full. A bulk call that already waits gets it too when an interactive call
+// takes its place in the queue.
+var ErrQueueFull = status.Error(codes.ResourceExhausted, "embedder queue is full")
+
+// LimiterStats describes the limiter at one point in time.
+type LimiterStats struct {
+	Limit             int    `json:"limit"`
+	Active            int    `json:"active"`
+	MaxQueue          int    `json:"max_queue"`
+	Queued            int    `json:"queued"`
+	QueuedInteractive int    `json:"queued_interactive"`
+	QueuedBulk        int    `json:"queued_bulk"`
+	Served            uint64 `json:"served"`
+	Rejected          uint64 `json:"rejected"`
+}
+
+// Limiter allows at most limit calls at a time. Further calls wait in a
+// queue of at most maxQueue entries, interactive ones ahead of bulk ones. When
+// the queue is full, an interactive call evicts the newest bulk waiter, so
+// bulk work cannot lock search out of the embedder.
+type Limiter struct {
+	limit    int
+	maxQueue int
+
+	mu     sync.Mutex
+	active int
+	// queues holds a list of waiter channels per Priority. A waiter receives
+	// nil when it is handed a slot and ErrQueueFull when it is evicted.
+	queues [2]*list.List
+
+	served   atomic.Uint64
+	rejected atomic.Uint64
+}
+
+func NewLimiter(limit, maxQueue int) *Limiter {
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &Limiter{
+		limit:    limit,
+		maxQueue: maxQueue,
+		queues:   [2]*list.List{list.New(), list.New()},
+	}
+}
+
+// Acquire waits for a free slot. The returned release must be called once the
+// call is done.
+func (l *Limiter) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	if priority != PriorityInteractive {
+		priority = PriorityBulk
+	}
+
+	l.mu.Lock()
+	if l.active < l.limit && l.queued() == 0 {
+		l.active++
+		l.mu.Unlock()
+		l.served.Add(1)
+		return l.release, nil
+	}
+	if l.queued() >= l.maxQueue && !l.evictBulk(priority) {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return nil, ErrQueueFull
+	}
+
+	ready := make(chan error, 1)
+	elem := l.queues[priority].PushBack(ready)
+	l.mu.Unlock()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return nil, err
+		}
+		l.served.Add(1)
+		return l.release, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case err := <-ready:
+			l.mu.Unlock()
+			if err == nil {
+				// The slot was handed over while giving up; pass it on.
+				l.release()
+			}
+		default:
+			l.queues[priority].Remove(elem)
+			l.mu.Unlock()
+		}
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release hands the slot to the first waiter, interactive before bulk.
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, priority := range []Priority{PriorityInteractive, PriorityBulk} {
+		if front := l.queues[priority].Front(); front != nil {
+			l.queues[priority].Remove(front)
+			front.Value.(chan error) <- nil
+			return
+		}
+	}
+	l.active--
+}
+
+// evictBulk makes room in a full queue for an interactive call by failing the
+// newest bulk waiter. It reports whether a waiter was evicted. l.mu must be
+// held.
+func (l *Limiter) evictBulk(priority Priority) bool {
+	if priority != PriorityInteractive {
+		return false
+	}
+	back := l.queues[PriorityBulk].Back()
+	if back == nil {
+		return false
+	}
+	l.queues[PriorityBulk].Remove(back)
+	back.Value.(chan error) <- ErrQueueFull
+	l.rejected.Add(1)
+	return true
+}
+
+func (l *Limiter) queued() int {
+	return l.queues[PriorityInteractive].Len() + l.queues[PriorityBulk].Len()
+}
+
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return LimiterStats{
+		Limit:             l.limit,
+		Active:            l.active,
+		MaxQueue:          l.maxQueue,
+		Queued:            l.queued(),
+		QueuedInteractive: l.queues[PriorityInteractive].Len(),
+		QueuedBulk:        l.queues[PriorityBulk].Len(),
+		Served:            l.served.Load(),
+		Rejected:          l.rejected.Load(),
+	}
+}
+
+// UnaryLimitInterceptor runs Embed and EmbedBatch calls through l, using the
+// priority the client sent in its metadata.
+func UnaryLimitInterceptor(l *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		switch info.FullMethod {
+		case pb.Embedder_Embed_FullMethodName, pb.Embedder_EmbedBatch_FullMethodName:
+		default:
+			return handler(ctx, req)
+		}
+
+		release, err := l.Acquire(ctx, priorityFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// withPriority marks outgoing calls on ctx with priority.
+func withPriority(ctx context.Context, priority Priority) context.Context {
+	value := priorityBulk
+	if priority == PriorityInteractive {
+		value = priorityInteractive
+	}
+	return metadata.AppendToOutgoingContext(ctx, priorityHeader, value)
+}
+
+func priorityFromContext(ctx context.Context) Priority {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(priorityHeader) {
+		if value == priorityInteractive {
+			return PriorityInteractive
+		}
+	}
+	return PriorityBulk
+}
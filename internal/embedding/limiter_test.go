@@ -0,0 +1,257 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiterQueueBound(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		maxQueue int
+	}{
+		{name: "no queue", limit: 1, maxQueue: 0},
+		{name: "short queue", limit: 1, maxQueue: 2},
+		{name: "several slots", limit: 3, maxQueue: 4},
+		{name: "negative queue", limit: 2, maxQueue: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.limit, tt.maxQueue)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var releases []func()
+			for i := 0; i < tt.limit; i++ {
+				release, err := l.Acquire(ctx, PriorityBulk)
+				if err != nil {
+					t.Fatalf("Acquire %d: %v", i, err)
+				}
+				releases = append(releases, release)
+			}
+
+			queue := max(tt.maxQueue, 0)
+			done := make(chan error, queue)
+			for i := 0; i < queue; i++ {
+				go func() {
+					release, err := l.Acquire(ctx, PriorityBulk)
+					if err == nil {
+						release()
+					}
+					done <- err
+				}()
+			}
+			waitFor(t, "queued waiters", func() bool { return l.Stats().Queued == queue })
+
+			if _, err := l.Acquire(ctx, PriorityBulk); err != ErrQueueFull {
+				t.Fatalf("Acquire with full queue = %v, want ErrQueueFull", err)
+			}
+			if got := l.Stats().Rejected; got != 1 {
+				t.Fatalf("Rejected = %d, want 1", got)
+			}
+
+			for _, release := range releases {
+				release()
+			}
+			for i := 0; i < queue; i++ {
+				if err := <-done; err != nil {
+					t.Fatalf("queued Acquire: %v", err)
+				}
+			}
+
+			stats := l.Stats()
+			if stats.Active != 0 || stats.Queued != 0 {
+				t.Fatalf("Stats = %+v, want no active or queued calls", stats)
+			}
+			if want := uint64(tt.limit + queue); stats.Served != want {
+				t.Fatalf("Served = %d, want %d", stats.Served, want)
+			}
+		})
+	}
+}
+
+func TestLimiterInteractiveEvictsBulk(t *testing.T) {
+	tests := []struct {
+		name    string
+		queued  []Priority
+		evicted int // index of the evicted waiter, -1 when the call is rejected
+	}{
+		{name: "newest bulk waiter", queued: []Priority{PriorityBulk, PriorityBulk}, evicted: 1},
+		{name: "bulk behind interactive", queued: []Priority{PriorityBulk, PriorityInteractive}, evicted: 0},
+		{name: "only interactive waiters", queued: []Priority{PriorityInteractive, PriorityInteractive}, evicted: -1},
+		{name: "no queue", queued: nil, evicted: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(1, len(tt.queued))
+			ctx := context.Background()
+
+			release, err := l.Acquire(ctx, PriorityBulk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done := make([]chan error, len(tt.queued))
+			for i, priority := range tt.queued {
+				done[i] = make(chan error, 1)
+				go func(i int, priority Priority) {
+					release, err := l.Acquire(ctx, priority)
+					if err == nil {
+						release()
+					}
+					done[i] <- err
+				}(i, priority)
+				waitFor(t, "waiter to queue", func() bool { return l.Stats().Queued == i+1 })
+			}
+
+			interactive := make(chan error, 1)
+			go func() {
+				release, err := l.Acquire(ctx, PriorityInteractive)
+				if err == nil {
+					release()
+				}
+				interactive <- err
+			}()
+
+			if tt.evicted < 0 {
+				if err := <-interactive; err != ErrQueueFull {
+					t.Fatalf("interactive Acquire = %v, want ErrQueueFull", err)
+				}
+			} else {
+				if err := <-done[tt.evicted]; err != ErrQueueFull {
+					t.Fatalf("evicted Acquire = %v, want ErrQueueFull", err)
+				}
+				waitFor(t, "interactive call to queue", func() bool { return l.Stats().Queued == len(tt.queued) })
+			}
+			if got := l.Stats().Rejected; got != 1 {
+				t.Fatalf("Rejected = %d, want 1", got)
+			}
+
+			release()
+			if tt.evicted >= 0 {
+				if err := <-interactive; err != nil {
+					t.Fatalf("interactive Acquire: %v", err)
+				}
+			}
+			for i := range tt.queued {
+				if i == tt.evicted {
+					continue
+				}
+				if err := <-done[i]; err != nil {
+					t.Fatalf("queued Acquire %d: %v", i, err)
+				}
+			}
+
+			if stats := l.Stats(); stats.Active != 0 || stats.Queued != 0 {
+				t.Fatalf("Stats = %+v, want no active or queued calls", stats)
+			}
+		})
+	}
+}
+
+func TestLimiterPriorityOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		queued []Priority
+		want   []int
+	}{
+		{
+			name:   "interactive first",
+			queued: []Priority{PriorityBulk, PriorityBulk, PriorityInteractive},
+			want:   []int{2, 0, 1},
+		},
+		{
+			name:   "fifo within priority",
+			queued: []Priority{PriorityInteractive, PriorityBulk, PriorityInteractive, PriorityBulk},
+			want:   []int{0, 2, 1, 3},
+		},
+		{
+			name:   "unknown priority is bulk",
+			queued: []Priority{Priority(7), PriorityInteractive},
+			want:   []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(1, len(tt.queued))
+			ctx := context.Background()
+
+			release, err := l.Acquire(ctx, PriorityBulk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			served := make(chan int)
+			releases := make(chan func())
+			for i, priority := range tt.queued {
+				go func(i int, priority Priority) {
+					release, err := l.Acquire(ctx, priority)
+					if err != nil {
+						t.Errorf("Acquire %d: %v", i, err)
+						return
+					}
+					served <- i
+					releases <- release
+				}(i, priority)
+				waitFor(t, "waiter to queue", func() bool { return l.Stats().Queued == i+1 })
+			}
+
+			release()
+			for _, want := range tt.want {
+				if got := <-served; got != want {
+					t.Fatalf("served waiter %d, want %d", got, want)
+				}
+				(<-releases)()
+			}
+		})
+	}
+}
+
+func TestLimiterCancelWhileQueued(t *testing.T) {
+	l := NewLimiter(1, 1)
+	release, err := l.Acquire(context.Background(), PriorityBulk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := l.Acquire(ctx, PriorityInteractive)
+		done <- err
+	}()
+	waitFor(t, "waiter to queue", func() bool { return l.Stats().Queued == 1 })
+
+	cancel()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Fatalf("Acquire after cancel = %v, want Canceled", err)
+	}
+	if got := l.Stats().Queued; got != 0 {
+		t.Fatalf("Queued = %d, want 0", got)
+	}
+
+	release()
+	if got := l.Stats().Active; got != 0 {
+		t.Fatalf("Active = %d, want 0", got)
+	}
+}
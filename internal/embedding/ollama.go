@@ -15,14 +15,16 @@ type OllamaClient struct {
 	Model   string
 }
 
+// ollamaEmbedRequest.Input is either a single string or a list of strings.
 type ollamaEmbedRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+	Model string      `json:"model"`
+	Input interface{} `json:"input"`
 }
 
 type ollamaEmbedResponse struct {
 	Model      string      `json:"model"`
 	Embeddings [][]float32 `json:"embeddings"`
+	Error      string      `json:"error"`
 }
 
 func NewOllamaClient(url string, model string) *OllamaClient {
@@ -33,11 +35,27 @@ func NewOllamaClient(url string, model string) *OllamaClient {
 }
 
 func (c *OllamaClient) Embed(ctx context.Context, input string) ([]float32, error) {
+	embeddings, err := c.embed(ctx, input, 1)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch embeds all inputs in one request. The vectors are returned in
+// input order.
+func (c *OllamaClient) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	return c.embed(ctx, inputs, len(inputs))
+}
+
+// embed sends input to /api/embed and checks that count non-empty vectors
+// came back.
+func (c *OllamaClient) embed(ctx context.Context, input interface{}, count int) ([][]float32, error) {
 	url := fmt.Sprintf("%s/api/embed", c.ApiBase)
 
 	reqBody, err := json.Marshal(ollamaEmbedRequest{Model: c.Model, Input: input})
 	if err != nil {
-		return []float32{}, err
+		return nil, err
 	}
 
 	respBytes, err := c.sendRequest(ctx, url, reqBody)
@@ -48,10 +66,19 @@ func (c *OllamaClient) Embed(ctx context.Context, input string) ([]float32, erro
 	var respObj ollamaEmbedResponse
 	err = json.Unmarshal(respBytes, &respObj)
 	if err != nil {
-		return []float32{}, fmt.Errorf("error decoding response: %v, body: %s", err, string(respBytes))
+		return nil, fmt.Errorf("error decoding response: %v, body: %s", err, string(respBytes))
 	}
 
-	return respObj.Embeddings[0], nil // TODO need check error from server
+	if len(respObj.Embeddings) != count {
+		return nil, fmt.Errorf("expected %d embeddings, got %d: %w", count, len(respObj.Embeddings), ErrEmptyEmbedding)
+	}
+	for _, embedding := range respObj.Embeddings {
+		if len(embedding) == 0 {
+			return nil, ErrEmptyEmbedding
+		}
+	}
+
+	return respObj.Embeddings, nil
 }
 
 func (c *OllamaClient) sendRequest(ctx context.Context, url string, body []byte) ([]byte, error) {
@@ -67,5 +94,19 @@ func (c *OllamaClient) sendRequest(ctx context.Context, url string, body []byte)
 	}
 	defer resp.Body.Close()
 
-	return io.ReadAll(resp.Body)
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var respObj ollamaEmbedResponse
+		message := strings.TrimSpace(string(respBytes))
+		if json.Unmarshal(respBytes, &respObj) == nil && respObj.Error != "" {
+			message = respObj.Error
+		}
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	return respBytes, nil
 }
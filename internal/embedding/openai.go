@@ -0,0 +1,109 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIClient talks to any server implementing the OpenAI /v1/embeddings
+// API, such as llama.cpp or vLLM. ApiBase includes the version prefix, e.g.
+// http://localhost:8000/v1.
+type OpenAIClient struct {
+	ApiBase string
+	Model   string
+	APIKey  string
+}
+
+type openAIEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewOpenAIClient(url, model, apiKey string) *OpenAIClient {
+	return &OpenAIClient{
+		ApiBase: strings.TrimRight(url, "/"),
+		Model:   model,
+		APIKey:  apiKey,
+	}
+}
+
+func (c *OpenAIClient) Embed(ctx context.Context, input string) ([]float32, error) {
+	vectors, err := c.EmbedBatch(ctx, []string{input})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (c *OpenAIClient) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	reqBody, err := json.Marshal(openAIEmbedRequest{Model: c.Model, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.ApiBase+"/embeddings", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var respObj openAIEmbedResponse
+	decodeErr := json.Unmarshal(respBytes, &respObj)
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(respBytes))
+		if decodeErr == nil && respObj.Error != nil {
+			message = respObj.Error.Message
+		}
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Message: message}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("error decoding response: %v, body: %s", decodeErr, string(respBytes))
+	}
+
+	if len(respObj.Data) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d: %w", len(inputs), len(respObj.Data), ErrEmptyEmbedding)
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, d := range respObj.Data {
+		if d.Index < 0 || d.Index >= len(inputs) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for _, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, ErrEmptyEmbedding
+		}
+	}
+	return vectors, nil
+}
@@ -109,6 +109,305 @@ func (x *EmbedResponse) GetVector() []float32 {
 	return nil
 }
 
+type EmbedBatchItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbedBatchItem) Reset() {
+	*x = EmbedBatchItem{}
+	mi := &file_proto_embedder_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbedBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbedBatchItem) ProtoMessage() {}
+
+func (x *EmbedBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbedBatchItem.ProtoReflect.Descriptor instead.
+func (*EmbedBatchItem) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EmbedBatchItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EmbedBatchItem) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type EmbedBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*EmbedBatchItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbedBatchRequest) Reset() {
+	*x = EmbedBatchRequest{}
+	mi := &file_proto_embedder_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbedBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbedBatchRequest) ProtoMessage() {}
+
+func (x *EmbedBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbedBatchRequest.ProtoReflect.Descriptor instead.
+func (*EmbedBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EmbedBatchRequest) GetItems() []*EmbedBatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// EmbedBatchResult carries either the vector or the error for one item. code
+// is the google.rpc.Code of the error.
+type EmbedBatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Vector        []float32              `protobuf:"fixed32,2,rep,packed,name=vector,proto3" json:"vector,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Code          int32                  `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbedBatchResult) Reset() {
+	*x = EmbedBatchResult{}
+	mi := &file_proto_embedder_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbedBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbedBatchResult) ProtoMessage() {}
+
+func (x *EmbedBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbedBatchResult.ProtoReflect.Descriptor instead.
+func (*EmbedBatchResult) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EmbedBatchResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EmbedBatchResult) GetVector() []float32 {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+func (x *EmbedBatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EmbedBatchResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+type EmbedBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EmbedBatchResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbedBatchResponse) Reset() {
+	*x = EmbedBatchResponse{}
+	mi := &file_proto_embedder_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbedBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbedBatchResponse) ProtoMessage() {}
+
+func (x *EmbedBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbedBatchResponse.ProtoReflect.Descriptor instead.
+func (*EmbedBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EmbedBatchResponse) GetResults() []*EmbedBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type InfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoRequest) Reset() {
+	*x = InfoRequest{}
+	mi := &file_proto_embedder_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoRequest) ProtoMessage() {}
+
+func (x *InfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{6}
+}
+
+// InfoResponse identifies the model that produces the vectors.
+type InfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Dimension     int32                  `protobuf:"varint,2,opt,name=dimension,proto3" json:"dimension,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoResponse) Reset() {
+	*x = InfoResponse{}
+	mi := &file_proto_embedder_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoResponse) ProtoMessage() {}
+
+func (x *InfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_embedder_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
+func (*InfoResponse) Descriptor() ([]byte, []int) {
+	return file_proto_embedder_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *InfoResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetDimension() int32 {
+	if x != nil {
+		return x.Dimension
+	}
+	return 0
+}
+
 var File_proto_embedder_proto protoreflect.FileDescriptor
 
 const file_proto_embedder_proto_rawDesc = "" +
@@ -117,9 +416,28 @@ const file_proto_embedder_proto_rawDesc = "" +
 	"\fEmbedRequest\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text\"'\n" +
 	"\rEmbedResponse\x12\x16\n" +
-	"\x06vector\x18\x01 \x03(\x02R\x06vector2F\n" +
+	"\x06vector\x18\x01 \x03(\x02R\x06vector\"4\n" +
+	"\x0eEmbedBatchItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"D\n" +
+	"\x11EmbedBatchRequest\x12/\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.embedding.EmbedBatchItemR\x05items\"d\n" +
+	"\x10EmbedBatchResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06vector\x18\x02 \x03(\x02R\x06vector\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\x05R\x04code\"K\n" +
+	"\x12EmbedBatchResponse\x125\n" +
+	"\aresults\x18\x01 \x03(\v2\x1b.embedding.EmbedBatchResultR\aresults\"\r\n" +
+	"\vInfoRequest\"B\n" +
+	"\fInfoResponse\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x1c\n" +
+	"\tdimension\x18\x02 \x01(\x05R\tdimension2\xca\x01\n" +
 	"\bEmbedder\x12:\n" +
-	"\x05Embed\x12\x17.embedding.EmbedRequest\x1a\x18.embedding.EmbedResponseB Z\x1enewstrix/internal/embedding/pbb\x06proto3"
+	"\x05Embed\x12\x17.embedding.EmbedRequest\x1a\x18.embedding.EmbedResponse\x12I\n" +
+	"\n" +
+	"EmbedBatch\x12\x1c.embedding.EmbedBatchRequest\x1a\x1d.embedding.EmbedBatchResponse\x127\n" +
+	"\x04Info\x12\x16.embedding.InfoRequest\x1a\x17.embedding.InfoResponseB Z\x1enewstrix/internal/embedding/pbb\x06proto3"
 
 var (
 	file_proto_embedder_proto_rawDescOnce sync.Once
@@ -133,19 +451,31 @@ func file_proto_embedder_proto_rawDescGZIP() []byte {
 	return file_proto_embedder_proto_rawDescData
 }
 
-var file_proto_embedder_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_embedder_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_proto_embedder_proto_goTypes = []any{
-	(*EmbedRequest)(nil),  // 0: embedding.EmbedRequest
-	(*EmbedResponse)(nil), // 1: embedding.EmbedResponse
+	(*EmbedRequest)(nil),       // 0: embedding.EmbedRequest
+	(*EmbedResponse)(nil),      // 1: embedding.EmbedResponse
+	(*EmbedBatchItem)(nil),     // 2: embedding.EmbedBatchItem
+	(*EmbedBatchRequest)(nil),  // 3: embedding.EmbedBatchRequest
+	(*EmbedBatchResult)(nil),   // 4: embedding.EmbedBatchResult
+	(*EmbedBatchResponse)(nil), // 5: embedding.EmbedBatchResponse
+	(*InfoRequest)(nil),        // 6: embedding.InfoRequest
+	(*InfoResponse)(nil),       // 7: embedding.InfoResponse
 }
 var file_proto_embedder_proto_depIdxs = []int32{
-	0, // 0: embedding.embedder.Embed:input_type -> embedding.EmbedRequest
-	1, // 1: embedding.embedder.Embed:output_type -> embedding.EmbedResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: embedding.EmbedBatchRequest.items:type_name -> embedding.EmbedBatchItem
+	4, // 1: embedding.EmbedBatchResponse.results:type_name -> embedding.EmbedBatchResult
+	0, // 2: embedding.Embedder.Embed:input_type -> embedding.EmbedRequest
+	3, // 3: embedding.Embedder.EmbedBatch:input_type -> embedding.EmbedBatchRequest
+	6, // 4: embedding.Embedder.Info:input_type -> embedding.InfoRequest
+	1, // 5: embedding.Embedder.Embed:output_type -> embedding.EmbedResponse
+	5, // 6: embedding.Embedder.EmbedBatch:output_type -> embedding.EmbedBatchResponse
+	7, // 7: embedding.Embedder.Info:output_type -> embedding.InfoResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proto_embedder_proto_init() }
@@ -159,7 +489,7 @@ func file_proto_embedder_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_embedder_proto_rawDesc), len(file_proto_embedder_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,52 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+)
+
+// Provider turns texts into vectors. Implementations are selected by
+// ProviderConfig.Kind.
+type Provider interface {
+	Embed(ctx context.Context, input string) ([]float32, error)
+	// EmbedBatch returns one vector per input, in input order.
+	EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+	ProviderHash   = "hash"
+)
+
+type ProviderConfig struct {
+	Kind string
+
+	OllamaURL   string
+	OllamaModel string
+
+	OpenAIURL    string
+	OpenAIModel  string
+	OpenAIAPIKey string
+
+	HashDimension int
+}
+
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch cfg.Kind {
+	case ProviderOllama, "":
+		return NewOllamaClient(cfg.OllamaURL, cfg.OllamaModel), nil
+	case ProviderOpenAI:
+		if cfg.OpenAIURL == "" || cfg.OpenAIModel == "" {
+			return nil, fmt.Errorf("openai provider requires a base url and a model")
+		}
+		return NewOpenAIClient(cfg.OpenAIURL, cfg.OpenAIModel, cfg.OpenAIAPIKey), nil
+	case ProviderHash:
+		if cfg.HashDimension <= 0 {
+			return nil, fmt.Errorf("hash provider requires a positive dimension, got %d", cfg.HashDimension)
+		}
+		return NewHashEmbedder(cfg.HashDimension), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,177 @@
+package embedding
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// healthMethodPrefix is exempt from token checks so that orchestration can
+// probe the embedder without a secret.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// ServerTransport secures the embedder server. TLS is enabled when CertFile
+// and KeyFile are set; ClientCAFile additionally requires clients to present a
+// certificate signed by it (mTLS). Token, when set, must be sent by clients as
+// a bearer token.
+type ServerTransport struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	Token        string
+}
+
+// ClientTransport secures the connection to the embedder. TLS is enabled when
+// TLS is set or any file is given; CAFile verifies the server, CertFile and
+// KeyFile are presented for mTLS.
+type ClientTransport struct {
+	TLS        bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	Token      string
+}
+
+// ServerOptions returns the gRPC server options for t.
+func ServerOptions(t ServerTransport) ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load server certificate: %w", err)
+		}
+		tlsConfig := &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		if t.ClientCAFile != "" {
+			pool, err := loadCertPool(t.ClientCAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.ClientCAs = pool
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if t.ClientCAFile != "" {
+		return nil, fmt.Errorf("client CA requires a server certificate and key")
+	}
+
+	if t.Token != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(UnaryTokenInterceptor(t.Token)),
+			grpc.ChainStreamInterceptor(StreamTokenInterceptor(t.Token)),
+		)
+	}
+	return opts, nil
+}
+
+// DialOptions returns the gRPC dial options for t.
+func DialOptions(t ClientTransport) ([]grpc.DialOption, error) {
+	secure := t.TLS || t.CAFile != "" || t.CertFile != "" || t.KeyFile != ""
+
+	creds := insecure.NewCredentials()
+	if secure {
+		tlsConfig := &tls.Config{
+			ServerName: t.ServerName,
+			MinVersion: tls.VersionTLS12,
+		}
+		if t.CAFile != "" {
+			pool, err := loadCertPool(t.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if t.CertFile != "" || t.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if t.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: t.Token, secure: secure}))
+	}
+	return opts, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// tokenCredentials sends the token as a bearer token with every call.
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+// RequireTransportSecurity lets the token travel over a plaintext connection
+// only when TLS is not configured at all.
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// UnaryTokenInterceptor rejects calls without the bearer token.
+func UnaryTokenInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, info.FullMethod, token); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTokenInterceptor rejects streams without the bearer token.
+func StreamTokenInterceptor(token string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), info.FullMethod, token); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, method, token string) error {
+	if strings.HasPrefix(method, healthMethodPrefix) {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		got, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
@@ -0,0 +1,109 @@
+package fetch
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BacklogPolicy controls the worker that vectorizes items stored as pending.
+type BacklogPolicy struct {
+	// Interval is how often the worker looks for due items.
+	Interval time.Duration
+	// BatchSize is how many items are claimed at once.
+	BatchSize int
+	// Lease is how long claimed items are hidden from other workers.
+	Lease time.Duration
+	// BaseDelay is the retry delay after the first failed attempt; it
+	// doubles with every further attempt up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func DefaultBacklogPolicy() BacklogPolicy {
+	return BacklogPolicy{
+		Interval:  30 * time.Second,
+		BatchSize: 64,
+		Lease:     5 * time.Minute,
+		BaseDelay: time.Minute,
+		MaxDelay:  time.Hour,
+	}
+}
+
+// WithBacklogPolicy sets how pending items are retried.
+func WithBacklogPolicy(p BacklogPolicy) Option {
+	return func(f *Fetcher) {
+		f.backlog = p
+	}
+}
+
+// StartBacklog vectorizes pending items until ctx is done.
+func (f *Fetcher) StartBacklog(ctx context.Context) {
+	ticker := time.NewTicker(f.backlog.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := f.DrainBacklog(ctx); err != nil {
+			log.Printf("Embedding backlog run failed: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DrainBacklog vectorizes due pending items until none are left and returns
+// how many got their vectors. Items that fail again are deferred.
+func (f *Fetcher) DrainBacklog(ctx context.Context) (int, error) {
+	done := 0
+	for ctx.Err() == nil {
+		items, err := f.storage.ClaimPendingNews(ctx, f.backlog.BatchSize, f.backlog.Lease)
+		if err != nil {
+			return done, err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		vectorized, pending := f.vectorizeItems(ctx, items, 1)
+
+		if f.clusterer != nil && len(vectorized) > 0 {
+			if err := f.clusterer.Assign(ctx, vectorized, nil); err != nil {
+				log.Printf("Failed to cluster backlog items: %v", err)
+			}
+		}
+
+		if len(vectorized) > 0 {
+			stored, err := f.storage.CompletePendingNews(ctx, vectorized)
+			if err != nil {
+				return done, err
+			}
+			if stale := len(vectorized) - stored; stale > 0 {
+				log.Printf("Skipped %d pending items that were revised while being vectorized", stale)
+			}
+			done += stored
+		}
+
+		if len(pending) > 0 {
+			if err := f.storage.DeferPendingNews(ctx, pending, f.backlog.BaseDelay, f.backlog.MaxDelay); err != nil {
+				return done, err
+			}
+			// The embedder is most likely still down; wait for the next run.
+			if len(vectorized) == 0 {
+				break
+			}
+		}
+	}
+
+	if done > 0 {
+		remaining, err := f.storage.CountPendingNews(ctx)
+		if err != nil {
+			return done, err
+		}
+		log.Printf("Vectorized %d pending items, %d still pending", done, remaining)
+	}
+	return done, nil
+}
@@ -0,0 +1,179 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultMaxBytes = 2 << 20
+
+	minParagraphLength = 40
+)
+
+// Rule overrides extraction for a single publisher.
+type Rule struct {
+	Selector string
+	Disabled bool
+}
+
+// DefaultRules holds CSS selectors for the article body of known publishers.
+var DefaultRules = map[string]Rule{
+	"Lenta.ru": {Selector: ".topic-body__content"},
+	"Ria.ru":   {Selector: ".article__body .article__text"},
+	"Tass.ru":  {Selector: "article [class*='Paragraph'], article .text-content"},
+}
+
+var (
+	boilerplateTags = "script, style, noscript, iframe, svg, form, button, nav, header, footer, aside, figure figcaption"
+	boilerplateAttr = regexp.MustCompile(`(?i)comment|share|social|related|banner|advert|promo|subscribe|footer|header|menu|navigation|sidebar|breadcrumb|tags`)
+	spaces          = regexp.MustCompile(`[ \t\r\f\v\x{00a0}]+`)
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+)
+
+// Extractor downloads article pages and returns their cleaned body text.
+type Extractor struct {
+	client   *http.Client
+	rules    map[string]Rule
+	maxBytes int64
+}
+
+func NewExtractor(client *http.Client, rules map[string]Rule, maxBytes int64) *Extractor {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	merged := make(map[string]Rule, len(DefaultRules)+len(rules))
+	for publisher, rule := range DefaultRules {
+		merged[publisher] = rule
+	}
+	for publisher, rule := range rules {
+		merged[publisher] = rule
+	}
+
+	return &Extractor{
+		client:   client,
+		rules:    merged,
+		maxBytes: maxBytes,
+	}
+}
+
+// Enabled reports whether full text should be extracted for the publisher.
+func (e *Extractor) Enabled(publisher string) bool {
+	return !e.rules[publisher].Disabled
+}
+
+func (e *Extractor) Extract(ctx context.Context, link string, publisher string) (string, error) {
+	doc, err := e.load(ctx, link)
+	if err != nil {
+		return "", err
+	}
+
+	doc.Find(boilerplateTags).Remove()
+
+	if rule, ok := e.rules[publisher]; ok && rule.Selector != "" {
+		if text := textFromSelection(doc.Find(rule.Selector)); text != "" {
+			return text, nil
+		}
+	}
+
+	doc.Find("[class], [id]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		if goquery.NodeName(s) == "body" || goquery.NodeName(s) == "html" {
+			return false
+		}
+		class, _ := s.Attr("class")
+		id, _ := s.Attr("id")
+		return boilerplateAttr.MatchString(class + " " + id)
+	}).Remove()
+
+	if text := textFromSelection(bestCandidate(doc)); text != "" {
+		return text, nil
+	}
+
+	return "", fmt.Errorf("no article text found at %s", link)
+}
+
+func (e *Extractor) load(ctx context.Context, link string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, link)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, e.maxBytes), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect charset for %s: %w", link, err)
+	}
+
+	return goquery.NewDocumentFromReader(body)
+}
+
+// bestCandidate picks the element whose direct paragraphs hold the most text,
+// which is how readability-style extractors find the article body.
+func bestCandidate(doc *goquery.Document) *goquery.Selection {
+	var best *goquery.Selection
+	bestScore := 0
+
+	doc.Find("p").Parent().Each(func(_ int, s *goquery.Selection) {
+		score := 0
+		s.ChildrenFiltered("p").Each(func(_ int, p *goquery.Selection) {
+			if n := len([]rune(strings.TrimSpace(p.Text()))); n >= minParagraphLength {
+				score += n
+			}
+		})
+		if score > bestScore {
+			best, bestScore = s, score
+		}
+	})
+
+	return best
+}
+
+func textFromSelection(sel *goquery.Selection) string {
+	if sel == nil || sel.Length() == 0 {
+		return ""
+	}
+
+	var paragraphs []string
+	sel.Each(func(_ int, s *goquery.Selection) {
+		ps := s.Find("p")
+		if goquery.NodeName(s) == "p" || ps.Length() == 0 {
+			paragraphs = appendText(paragraphs, s.Text())
+			return
+		}
+		ps.Each(func(_ int, p *goquery.Selection) {
+			paragraphs = appendText(paragraphs, p.Text())
+		})
+	})
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func appendText(paragraphs []string, text string) []string {
+	text = spaces.ReplaceAllString(text, " ")
+	text = blankLines.ReplaceAllString(strings.TrimSpace(text), "\n\n")
+	if text == "" {
+		return paragraphs
+	}
+	return append(paragraphs, text)
+}
@@ -0,0 +1,136 @@
+package extract
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	paragraph1 = "The first paragraph of the article is long enough to count."
+	paragraph2 = "The second paragraph of the article is long enough as well."
+	comment    = "A reader comment that is even longer than any paragraph of the article itself."
+)
+
+var pages = map[string]string{
+	"/rule": `<html><body>
+		<div class="topic-body__content"><p>Selected by the publisher rule.</p></div>
+		<div><p>` + paragraph1 + `</p><p>` + paragraph2 + `</p></div>
+	</body></html>`,
+	"/custom": `<html><body>
+		<div class="body"><p>Selected by a configured rule.</p></div>
+		<div><p>` + paragraph1 + `</p></div>
+	</body></html>`,
+	"/readability": `<html><body>
+		<nav><p>` + comment + `</p></nav>
+		<div class="comments"><p>` + comment + `</p><p>` + comment + `</p></div>
+		<div id="article"><p>` + paragraph1 + `</p>
+			<script>var x = "not text";</script>
+			<p>` + paragraph2 + `</p><p>Short.</p></div>
+		<footer><p>` + comment + `</p></footer>
+	</body></html>`,
+	"/spaces": `<html><body><div><p>  The first   paragraph of the article&nbsp;is long enough to count.  </p></div></body></html>`,
+	"/empty":  `<html><body><p>Too short.</p></body></html>`,
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cp1251" {
+			w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+			w.Write(cp1251("<html><body><div><p>Первый абзац статьи в кодировке windows-1251 достаточно длинный.</p></div></body></html>"))
+			return
+		}
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExtract(t *testing.T) {
+	srv := newServer(t)
+	e := NewExtractor(srv.Client(), map[string]Rule{"Example": {Selector: ".body"}}, 0)
+
+	tests := []struct {
+		name      string
+		path      string
+		publisher string
+		want      string
+		wantErr   bool
+	}{
+		{name: "default rule", path: "/rule", publisher: "Lenta.ru", want: "Selected by the publisher rule."},
+		{name: "configured rule", path: "/custom", publisher: "Example", want: "Selected by a configured rule."},
+		{name: "rule of other publisher", path: "/custom", publisher: "Lenta.ru", want: paragraph1},
+		{name: "best candidate", path: "/readability", publisher: "Other", want: paragraph1 + "\n\n" + paragraph2 + "\n\nShort."},
+		{name: "spaces collapsed", path: "/spaces", publisher: "Other", want: paragraph1},
+		{name: "charset", path: "/cp1251", publisher: "Other", want: "Первый абзац статьи в кодировке windows-1251 достаточно длинный."},
+		{name: "no text", path: "/empty", publisher: "Other", wantErr: true},
+		{name: "not found", path: "/missing", publisher: "Other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Extract(context.Background(), srv.URL+tt.path, tt.publisher)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Extract error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Extract = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMaxBytes(t *testing.T) {
+	srv := newServer(t)
+	e := NewExtractor(srv.Client(), nil, int64(strings.Index(pages["/readability"], "<div class=\"comments\">")))
+
+	if _, err := e.Extract(context.Background(), srv.URL+"/readability", "Other"); err == nil {
+		t.Fatal("Extract read past the size limit")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	e := NewExtractor(nil, map[string]Rule{"Off": {Disabled: true}, "Tass.ru": {Disabled: true}}, 0)
+
+	tests := []struct {
+		publisher string
+		want      bool
+	}{
+		{publisher: "Off", want: false},
+		{publisher: "Tass.ru", want: false},
+		{publisher: "Lenta.ru", want: true},
+		{publisher: "Unknown", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.publisher, func(t *testing.T) {
+			if got := e.Enabled(tt.publisher); got != tt.want {
+				t.Fatalf("Enabled(%q) = %v, want %v", tt.publisher, got, tt.want)
+			}
+		})
+	}
+}
+
+// cp1251 encodes ASCII and basic Cyrillic text as windows-1251.
+func cp1251(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			b = append(b, byte(r))
+		case r >= 'А' && r <= 'я':
+			b = append(b, byte(0xC0+r-'А'))
+		default:
+			panic("cp1251: unsupported rune " + string(r))
+		}
+	}
+	return b
+}
@@ -2,9 +2,16 @@ package fetch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"newstrix/internal/chunk"
+	"newstrix/internal/cluster"
 	"newstrix/internal/embedding"
+	"newstrix/internal/fetch/extract"
+	"newstrix/internal/fetch/normalize"
+	"newstrix/internal/fetch/schedule"
 	"newstrix/internal/models"
 	"newstrix/internal/storage"
 	"sync"
@@ -16,24 +23,111 @@ type Fetcher struct {
 	embedder   *embedding.Embedder
 	storage    storage.Facade
 	maxWorkers int
+	extractor  *extract.Extractor
+	overlap    time.Duration
+	schedules  map[string]schedule.Spec
+	health     HealthPolicy
+	clusterer  *cluster.Clusterer
+	chunker    *chunk.Chunker
+	batchSize  int
+	backlog    BacklogPolicy
 	stats      *FetchStats
 }
 
+// DefaultEmbedBatchSize is how many items are sent in one embedding call.
+const DefaultEmbedBatchSize = 32
+
+// inlineVectorizeAttempts is how often a batch is tried during a fetch run.
+// Only transient failures are retried. Items are stored as pending after that
+// and retried by the backlog worker, so a fetch run does not wait out an
+// embedder outage.
+const inlineVectorizeAttempts = 2
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+type Option func(*Fetcher)
+
+// WithExtractor enables downloading the full article text for every new item.
+func WithExtractor(e *extract.Extractor) Option {
+	return func(f *Fetcher) {
+		f.extractor = e
+	}
+}
+
+// WithWatermarkOverlap sets how far before the stored watermark each source is
+// re-read. Items that are already stored are skipped before vectorization.
+func WithWatermarkOverlap(d time.Duration) Option {
+	return func(f *Fetcher) {
+		f.overlap = d
+	}
+}
+
+// WithSchedules sets per-source polling schedules by source name. Sources
+// without an entry are polled at the interval passed to Start.
+func WithSchedules(specs map[string]schedule.Spec) Option {
+	return func(f *Fetcher) {
+		f.schedules = specs
+	}
+}
+
+// WithHealthPolicy sets when failing sources are quarantined.
+func WithHealthPolicy(p HealthPolicy) Option {
+	return func(f *Fetcher) {
+		f.health = p
+	}
+}
+
+// WithClusterer assigns every vectorized item to a story cluster.
+func WithClusterer(c *cluster.Clusterer) Option {
+	return func(f *Fetcher) {
+		f.clusterer = c
+	}
+}
+
+// WithEmbedBatchSize sets how many items of a source are embedded in one call.
+func WithEmbedBatchSize(n int) Option {
+	return func(f *Fetcher) {
+		f.batchSize = n
+	}
+}
+
+// WithChunker embeds the body of every item in passages as well, for
+// passage-level search.
+func WithChunker(c *chunk.Chunker) Option {
+	return func(f *Fetcher) {
+		f.chunker = c
+	}
+}
+
 type FetchStats struct {
 	mu              sync.RWMutex
 	TotalSources    int
 	SuccessfulFetch int
 	FailedFetch     int
+	NotModified     int
+	Quarantined     int
 	TotalItems      int
+	SkippedItems    int
+	UpdatedItems    int
+	DroppedItems    int
+	ExtractedItems  int
 	VectorizedItems int
-	FailedItems     int
+	PendingItems    int
 	LastRunTime     time.Time
 }
 
 type FetchResult struct {
-	Source string
-	Items  []models.NewsItem
-	Error  error
+	Source      string
+	Items       []models.NewsItem
+	LastParsed  time.Time
+	KnownUntil  time.Time
+	NotModified bool
+	Quarantined bool
+	Validators  *models.CacheValidators
+	Error       error
 }
 
 type ProcessedItem struct {
@@ -42,34 +136,58 @@ type ProcessedItem struct {
 	Error  error
 }
 
-func NewFetcher(s []models.Source, e *embedding.Embedder, storage storage.Facade, maxWorkers int) *Fetcher {
-	return &Fetcher{
+func NewFetcher(s []models.Source, e *embedding.Embedder, storage storage.Facade, maxWorkers int, opts ...Option) *Fetcher {
+	f := &Fetcher{
 		sources:    s,
 		embedder:   e,
 		storage:    storage,
 		maxWorkers: maxWorkers,
+		overlap:    DefaultWatermarkOverlap,
+		health:     DefaultHealthPolicy(),
+		batchSize:  DefaultEmbedBatchSize,
+		backlog:    DefaultBacklogPolicy(),
 		stats:      &FetchStats{TotalSources: len(s)},
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.batchSize < 1 {
+		f.batchSize = 1
+	}
+	return f
 }
 
+// Run fetches all sources once.
 func (f *Fetcher) Run(ctx context.Context) error {
+	_, err := f.runSources(ctx, f.sources)
+	return err
+}
+
+func (f *Fetcher) runSources(ctx context.Context, srcs []models.Source) (map[string]schedule.Outcome, error) {
 	startTime := time.Now()
-	log.Printf("Starting concurrent fetch for %d sources with %d workers", len(f.sources), f.maxWorkers)
+	log.Printf("Starting concurrent fetch for %d sources with %d workers", len(srcs), f.maxWorkers)
 
 	// Reset stats for this run
 	f.stats.mu.Lock()
 	f.stats.LastRunTime = startTime
+	f.stats.TotalSources = len(srcs)
 	f.stats.SuccessfulFetch = 0
 	f.stats.FailedFetch = 0
+	f.stats.NotModified = 0
+	f.stats.Quarantined = 0
 	f.stats.TotalItems = 0
+	f.stats.SkippedItems = 0
+	f.stats.UpdatedItems = 0
+	f.stats.DroppedItems = 0
+	f.stats.ExtractedItems = 0
 	f.stats.VectorizedItems = 0
-	f.stats.FailedItems = 0
+	f.stats.PendingItems = 0
 	f.stats.mu.Unlock()
 
-	sourceResults := make(chan FetchResult, len(f.sources))
-	
+	sourceResults := make(chan FetchResult, len(srcs))
+
 	var wg sync.WaitGroup
-	for _, source := range f.sources {
+	for _, source := range srcs {
 		wg.Add(1)
 		go f.fetchSource(ctx, source, sourceResults, &wg)
 	}
@@ -79,23 +197,42 @@ func (f *Fetcher) Run(ctx context.Context) error {
 		close(sourceResults)
 	}()
 
-	err := f.processAndStore(ctx, sourceResults)
+	outcomes, err := f.processAndStore(ctx, sourceResults)
 
 	duration := time.Since(startTime)
 	f.stats.mu.RLock()
-	log.Printf("Fetch completed in %v. Sources: %d/%d, Items: %d/%d, Vectorized: %d/%d",
+	log.Printf("Fetch completed in %v. Sources: %d/%d (%d not modified, %d quarantined), Items: %d/%d, Vectorized: %d/%d, Pending: %d",
 		duration,
-		f.stats.SuccessfulFetch, f.stats.TotalSources,
+		f.stats.SuccessfulFetch, f.stats.TotalSources, f.stats.NotModified, f.stats.Quarantined,
 		f.stats.VectorizedItems, f.stats.TotalItems,
-		f.stats.VectorizedItems, f.stats.TotalItems)
+		f.stats.VectorizedItems, f.stats.TotalItems, f.stats.PendingItems)
+	if cache := f.embedder.CacheStats(); cache != nil {
+		log.Printf("Embedding cache: %d hits, %d store hits, %d misses, %d entries",
+			cache.Hits, cache.StoreHits, cache.Misses, cache.Size)
+	}
 	f.stats.mu.RUnlock()
 
-	return err
+	return outcomes, err
 }
 
 func (f *Fetcher) fetchSource(ctx context.Context, source models.Source, results chan<- FetchResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	health, err := f.storage.GetSourceHealth(ctx, source.Name())
+	if err != nil {
+		log.Printf("Error getting health for source %s: %v", source.Name(), err)
+		health = &models.SourceHealth{Source: source.Name()}
+	}
+
+	if health.Quarantined(time.Now()) {
+		log.Printf("Source %s is quarantined until %s after %d failures", source.Name(), health.QuarantinedUntil.Format(time.RFC3339), health.ConsecutiveFailures)
+		f.stats.mu.Lock()
+		f.stats.Quarantined++
+		f.stats.mu.Unlock()
+		results <- FetchResult{Source: source.Name(), Quarantined: true}
+		return
+	}
+
 	log.Printf("Fetching from source: %s", source.Name())
 
 	lastParsed, err := f.storage.GetSourceLastParsed(ctx, source.Name())
@@ -108,7 +245,19 @@ func (f *Fetcher) fetchSource(ctx context.Context, source models.Source, results
 		return
 	}
 
-	items, err := source.Fetch(ctx, lastParsed)
+	start := time.Now()
+	items, validators, err := f.fetchItems(ctx, source, since(lastParsed, f.overlap))
+	f.recordHealth(ctx, health, time.Since(start), items, err)
+
+	if errors.Is(err, models.ErrNotModified) {
+		log.Printf("Source %s not modified since last fetch", source.Name())
+		f.stats.mu.Lock()
+		f.stats.SuccessfulFetch++
+		f.stats.NotModified++
+		f.stats.mu.Unlock()
+		results <- FetchResult{Source: source.Name(), LastParsed: lastParsed, NotModified: true}
+		return
+	}
 	if err != nil {
 		log.Printf("Error fetching from source %s: %v", source.Name(), err)
 		f.stats.mu.Lock()
@@ -118,122 +267,450 @@ func (f *Fetcher) fetchSource(ctx context.Context, source models.Source, results
 		return
 	}
 
-	if len(*items) == 0 {
+	normalized, issues := normalize.Batch(*items)
+
+	newItems, known, conflicts, err := f.skipStored(ctx, normalized)
+	if err != nil {
+		log.Printf("Error checking stored items for source %s: %v", source.Name(), err)
+		f.stats.mu.Lock()
+		f.stats.FailedFetch++
+		f.stats.mu.Unlock()
+		results <- FetchResult{Source: source.Name(), Error: err}
+		return
+	}
+
+	f.reportIssues(source.Name(), append(issues, conflicts...))
+
+	result := FetchResult{
+		Source:     source.Name(),
+		Items:      newItems,
+		LastParsed: lastParsed,
+		KnownUntil: newestPublished(known),
+		Validators: validators,
+	}
+
+	if len(newItems) == 0 {
 		log.Printf("No new items for source %s since %s", source.Name(), lastParsed)
 		f.stats.mu.Lock()
 		f.stats.SuccessfulFetch++
+		f.stats.SkippedItems += len(known)
 		f.stats.mu.Unlock()
-		results <- FetchResult{Source: source.Name(), Items: []models.NewsItem{}}
+		results <- result
 		return
 	}
 
-	log.Printf("Fetched %d items from source %s", len(*items), source.Name())
+	log.Printf("Fetched %d new items from source %s (%d already stored)", len(newItems), source.Name(), len(known))
 	f.stats.mu.Lock()
 	f.stats.SuccessfulFetch++
-	f.stats.TotalItems += len(*items)
+	f.stats.TotalItems += len(newItems)
+	f.stats.SkippedItems += len(known)
 	f.stats.mu.Unlock()
-	results <- FetchResult{Source: source.Name(), Items: *items}
+	results <- result
 }
 
-func (f *Fetcher) processAndStore(ctx context.Context, sourceResults <-chan FetchResult) error {
-	sourceBatches := make(map[string][]models.NewsItem)
-	var mu sync.Mutex
+// recordHealth updates and saves the health record of a source after a fetch
+// attempt. Not modified responses count as successful runs without items.
+func (f *Fetcher) recordHealth(ctx context.Context, health *models.SourceHealth, latency time.Duration, items *[]models.NewsItem, err error) {
+	now := time.Now()
+	switch {
+	case err == nil:
+		f.health.recordSuccess(health, now, latency, len(*items))
+	case errors.Is(err, models.ErrNotModified):
+		f.health.recordSuccess(health, now, latency, 0)
+	case ctx.Err() != nil:
+		// Shutdown is not the source's fault.
+		return
+	default:
+		f.health.recordFailure(health, now, latency, err)
+		if health.Quarantined(now) {
+			log.Printf("Source %s quarantined until %s after %d consecutive failures", health.Source, health.QuarantinedUntil.Format(time.RFC3339), health.ConsecutiveFailures)
+		}
+	}
+
+	if err := f.storage.SaveSourceHealth(ctx, health); err != nil {
+		log.Printf("Error saving health for source %s: %v", health.Source, err)
+	}
+}
+
+// fetchItems uses a conditional request when the source supports it. The
+// returned validators are nil when they did not change.
+func (f *Fetcher) fetchItems(ctx context.Context, source models.Source, timeline time.Time) (*[]models.NewsItem, *models.CacheValidators, error) {
+	conditional, ok := source.(models.ConditionalSource)
+	if !ok {
+		items, err := source.Fetch(ctx, timeline)
+		return items, nil, err
+	}
+
+	prev, err := f.storage.GetSourceValidators(ctx, source.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, next, err := conditional.FetchConditional(ctx, timeline, prev)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if next == prev {
+		return items, nil, nil
+	}
+	return items, &next, nil
+}
+
+// skipStored splits fetched items into new ones and ones that are already in
+// storage, which happens for everything inside the overlap window. Stored
+// items whose content hash changed are treated as new so that the updated
+// version is extracted, re-embedded and stored as a new revision. New items
+// whose link is already stored under another id are reported as conflicts,
+// since inserting them would violate the unique link constraint.
+func (f *Fetcher) skipStored(ctx context.Context, items []models.NewsItem) ([]models.NewsItem, []models.NewsItem, []normalize.Issue, error) {
+	if len(items) == 0 {
+		return items, nil, nil, nil
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Guid)
+	}
+
+	hashes, err := f.storage.StoredHashes(ctx, ids)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var candidates, known []models.NewsItem
+	var links []string
+	updated := 0
+	for _, item := range items {
+		if hash, ok := hashes[item.Guid]; ok {
+			// Rows stored before hashing was introduced have no hash and are
+			// not treated as changed.
+			if hash == "" || hash == item.ContentHash {
+				known = append(known, item)
+				continue
+			}
+			updated++
+		}
+		candidates = append(candidates, item)
+		links = append(links, item.Link)
+	}
+
+	if updated > 0 {
+		f.stats.mu.Lock()
+		f.stats.UpdatedItems += updated
+		f.stats.mu.Unlock()
+	}
+
+	if len(candidates) == 0 {
+		return nil, known, nil, nil
+	}
+
+	owners, err := f.storage.LinkOwners(ctx, links)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var newItems []models.NewsItem
+	var conflicts []normalize.Issue
+	for _, item := range candidates {
+		if owner, ok := owners[item.Link]; ok && owner != item.Guid {
+			conflicts = append(conflicts, normalize.Issue{Item: item, Reason: normalize.ReasonDuplicateLink, ConflictID: owner})
+			continue
+		}
+		newItems = append(newItems, item)
+	}
+	return newItems, known, conflicts, nil
+}
+
+func (f *Fetcher) reportIssues(source string, issues []normalize.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	for _, issue := range issues {
+		log.Printf("Dropping item from source %s: %s", source, issue)
+	}
+
+	f.stats.mu.Lock()
+	f.stats.DroppedItems += len(issues)
+	f.stats.mu.Unlock()
+}
+
+func (f *Fetcher) processAndStore(ctx context.Context, sourceResults <-chan FetchResult) (map[string]schedule.Outcome, error) {
+	sourceBatches := make(map[string]*sourceBatch)
+	outcomes := make(map[string]schedule.Outcome)
+	// Different feeds may carry the same article; only the first one stored
+	// in a run keeps it.
+	runIDs := make(map[string]string)
+	runLinks := make(map[string]string)
+	var clustered []models.NewsItem
 
 	for result := range sourceResults {
 		if result.Error != nil {
 			log.Printf("Source %s failed: %v", result.Source, result.Error)
+			outcomes[result.Source] = schedule.OutcomeFailed
 			continue
 		}
 
+		if result.Quarantined {
+			outcomes[result.Source] = schedule.OutcomeFailed
+			continue
+		}
+
+		outcomes[result.Source] = schedule.OutcomeIdle
+		if result.NotModified {
+			continue
+		}
+
+		batch := &sourceBatch{
+			lastParsed: result.LastParsed,
+			knownUntil: result.KnownUntil,
+			validators: result.Validators,
+		}
+		sourceBatches[result.Source] = batch
+
 		if len(result.Items) == 0 {
 			continue
 		}
+		outcomes[result.Source] = schedule.OutcomeNewItems
 
-		vectorizedItems, err := f.vectorizeItems(ctx, result.Items)
-		if err != nil {
-			log.Printf("Failed to vectorize items for source %s: %v", result.Source, err)
-			f.stats.mu.Lock()
-			f.stats.FailedItems += len(result.Items)
-			f.stats.mu.Unlock()
+		items, issues := dedupeRun(result.Items, runIDs, runLinks)
+		f.reportIssues(result.Source, issues)
+		if len(items) == 0 {
 			continue
 		}
 
-		mu.Lock()
-		sourceBatches[result.Source] = append(sourceBatches[result.Source], vectorizedItems...)
-		mu.Unlock()
+		f.extractItems(ctx, items)
+
+		vectorized, pending := f.vectorizeItems(ctx, items, inlineVectorizeAttempts)
+
+		if f.clusterer != nil && len(vectorized) > 0 {
+			if err := f.clusterer.Assign(ctx, vectorized, clustered); err != nil {
+				log.Printf("Failed to cluster items for source %s: %v", result.Source, err)
+			}
+			clustered = append(clustered, vectorized...)
+		}
+
+		batch.stored = append(vectorized, pending...)
+		batch.pending = len(pending)
 
 		f.stats.mu.Lock()
-		f.stats.VectorizedItems += len(vectorizedItems)
+		f.stats.VectorizedItems += len(vectorized)
+		f.stats.PendingItems += len(pending)
 		f.stats.mu.Unlock()
 	}
 
-	return f.storeBatches(ctx, sourceBatches)
+	return outcomes, f.storeBatches(ctx, sourceBatches)
 }
 
-func (f *Fetcher) vectorizeItems(ctx context.Context, items []models.NewsItem) ([]models.NewsItem, error) {
-	var vectorizedItems []models.NewsItem
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+func dedupeRun(items []models.NewsItem, ids map[string]string, links map[string]string) ([]models.NewsItem, []normalize.Issue) {
+	var unique []models.NewsItem
+	var issues []normalize.Issue
+	for _, item := range items {
+		if owner, ok := ids[item.Guid]; ok {
+			issues = append(issues, normalize.Issue{Item: item, Reason: normalize.ReasonDuplicateID, ConflictID: owner})
+			continue
+		}
+		if owner, ok := links[item.Link]; ok {
+			issues = append(issues, normalize.Issue{Item: item, Reason: normalize.ReasonDuplicateLink, ConflictID: owner})
+			continue
+		}
+		ids[item.Guid] = item.Guid
+		links[item.Link] = item.Guid
+		unique = append(unique, item)
+	}
+	return unique, issues
+}
+
+// extractItems fills FullText in place. Extraction errors are not fatal: the
+// item is kept with its feed description only.
+func (f *Fetcher) extractItems(ctx context.Context, items []models.NewsItem) {
+	if f.extractor == nil {
+		return
+	}
 
+	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, f.maxWorkers)
 
 	for i := range items {
+		if !f.extractor.Enabled(items[i].Publisher) || items[i].Link == "" {
+			continue
+		}
+
 		wg.Add(1)
-		go func(item models.NewsItem) {
+		go func(item *models.NewsItem) {
 			defer wg.Done()
 
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			if err := f.VectorizeWithRetry(ctx, &item, 3); err != nil {
-				log.Printf("Failed to vectorize item %s: %v", item.Guid, err)
-				f.stats.mu.Lock()
-				f.stats.FailedItems++
-				f.stats.mu.Unlock()
+			text, err := f.extractor.Extract(ctx, item.Link, item.Publisher)
+			if err != nil {
+				log.Printf("Failed to extract full text for item %s: %v", item.Link, err)
 				return
 			}
 
-			// Add to results
+			item.FullText = text
+			f.stats.mu.Lock()
+			f.stats.ExtractedItems++
+			f.stats.mu.Unlock()
+		}(&items[i])
+	}
+
+	wg.Wait()
+}
+
+// vectorizeItems embeds items, and the chunks of their body when a chunker is
+// set, in batches of batchSize, trying each batch up to attempts times. It
+// returns the items that got all their vectors and the ones that did not,
+// marked pending.
+func (f *Fetcher) vectorizeItems(ctx context.Context, items []models.NewsItem, attempts int) ([]models.NewsItem, []models.NewsItem) {
+	info, err := f.embedder.Info(ctx)
+	if err != nil {
+		log.Printf("Failed to vectorize %d items, embedding model unknown: %v", len(items), err)
+		var pending []models.NewsItem
+		for _, item := range items {
+			pending = append(pending, markPending(item, err))
+		}
+		return nil, pending
+	}
+
+	var requests []embedding.BatchItem
+	for i := range items {
+		if f.chunker != nil {
+			items[i].Chunks = f.chunker.Chunks(items[i])
+		}
+		requests = append(requests, embedding.ItemRequests(items[i])...)
+	}
+
+	results := make(map[string]embedding.BatchResult, len(requests))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	semaphore := make(chan struct{}, f.maxWorkers)
+
+	for start := 0; start < len(requests); start += f.batchSize {
+		end := min(start+f.batchSize, len(requests))
+
+		wg.Add(1)
+		go func(batch []embedding.BatchItem) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			batchResults := f.vectorizeBatch(ctx, batch, attempts)
+
 			mu.Lock()
-			vectorizedItems = append(vectorizedItems, item)
+			for _, result := range batchResults {
+				results[result.ID] = result
+			}
 			mu.Unlock()
-		}(items[i])
+		}(requests[start:end])
 	}
 
 	wg.Wait()
-	return vectorizedItems, nil
+
+	var vectorizedItems, pendingItems []models.NewsItem
+	for _, item := range items {
+		if err := embedding.ApplyVectors(&item, results, info); err != nil {
+			log.Printf("Failed to vectorize item %s: %v", item.Guid, err)
+			pendingItems = append(pendingItems, markPending(item, err))
+			continue
+		}
+		vectorizedItems = append(vectorizedItems, item)
+	}
+
+	return vectorizedItems, pendingItems
 }
 
-func (f *Fetcher) storeBatches(ctx context.Context, sourceBatches map[string][]models.NewsItem) error {
+// markPending drops whatever vectors the item got so that it is stored
+// without any and picked up by the backlog worker.
+func markPending(item models.NewsItem, err error) models.NewsItem {
+	item.Vector = nil
+	item.EmbeddingModel = ""
+	item.EmbeddingDim = 0
+	item.Chunks = nil
+	item.EmbeddingStatus = models.EmbeddingPending
+	item.EmbeddingError = err.Error()
+	return item
+}
+
+// vectorizeBatch embeds one batch. If the call as a whole fails every request
+// of the batch gets its error.
+func (f *Fetcher) vectorizeBatch(ctx context.Context, batch []embedding.BatchItem, attempts int) []embedding.BatchResult {
+	results, err := f.VectorizeBatchWithRetry(ctx, batch, attempts)
+	if err == nil {
+		return results
+	}
+
+	log.Printf("Failed to vectorize batch of %d texts: %v", len(batch), err)
+	results = make([]embedding.BatchResult, len(batch))
+	for i, item := range batch {
+		results[i] = embedding.BatchResult{ID: item.ID, Err: err}
+	}
+	return results
+}
+
+func (f *Fetcher) storeBatches(ctx context.Context, sourceBatches map[string]*sourceBatch) error {
 	var wg sync.WaitGroup
 	var errors []error
+	var stored []models.NewsItem
 	var mu sync.Mutex
 
-	for source, items := range sourceBatches {
-		if len(items) == 0 {
+	for source, batch := range sourceBatches {
+		watermark := batch.watermark()
+		needsStore := len(batch.stored) > 0 || !watermark.Equal(batch.lastParsed)
+		saveValidators := batch.validators != nil
+		if !needsStore && !saveValidators {
 			continue
 		}
 
+		if batch.pending > 0 {
+			log.Printf("Storing %d items from source %s as pending, they are vectorized later", batch.pending, source)
+		}
+
 		wg.Add(1)
-		go func(sourceName string, sourceItems []models.NewsItem) {
+		go func(sourceName string, batch *sourceBatch, watermark time.Time) {
 			defer wg.Done()
 
-			log.Printf("Storing %d items from source %s", len(sourceItems), sourceName)
+			if needsStore {
+				log.Printf("Storing %d items from source %s", len(batch.stored), sourceName)
+
+				if err := f.storage.AddNews(ctx, &batch.stored, sourceName, watermark); err != nil {
+					log.Printf("Failed to store %d items from source %s: %v", len(batch.stored), sourceName, err)
+					mu.Lock()
+					errors = append(errors, fmt.Errorf("source %s: %w", sourceName, err))
+					mu.Unlock()
+					return
+				}
 
-			if err := f.storage.AddNews(ctx, &sourceItems, sourceName, time.Now()); err != nil {
-				log.Printf("Failed to store %d items from source %s: %v", len(sourceItems), sourceName, err)
+				log.Printf("Successfully stored %d items from source %s", len(batch.stored), sourceName)
 				mu.Lock()
-				errors = append(errors, fmt.Errorf("source %s: %w", sourceName, err))
+				stored = append(stored, batch.stored...)
 				mu.Unlock()
-				return
 			}
 
-			log.Printf("Successfully stored %d items from source %s", len(sourceItems), sourceName)
-		}(source, items)
+			if saveValidators {
+				if err := f.storage.UpdateSourceValidators(ctx, sourceName, *batch.validators); err != nil {
+					log.Printf("Failed to save cache validators for source %s: %v", sourceName, err)
+					mu.Lock()
+					errors = append(errors, fmt.Errorf("source %s: %w", sourceName, err))
+					mu.Unlock()
+				}
+			}
+		}(source, batch, watermark)
 	}
 
 	wg.Wait()
 
+	// Clusters are refreshed once all sources are committed, so that sources
+	// reporting the same story do not conflict on its cluster.
+	if err := f.storage.RefreshClusters(ctx, storage.ClusterIDs(stored)); err != nil {
+		log.Printf("Failed to refresh story clusters: %v", err)
+		errors = append(errors, err)
+	}
+
 	// Return first error if any occurred
 	if len(errors) > 0 {
 		return errors[0]
@@ -242,29 +719,119 @@ func (f *Fetcher) storeBatches(ctx context.Context, sourceBatches map[string][]m
 	return nil
 }
 
+// Start polls every source on its own schedule until ctx is cancelled.
+// Sources without a schedule are polled every interval.
 func (f *Fetcher) Start(ctx context.Context, interval time.Duration) error {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	specs := make(map[string]schedule.Spec, len(f.sources))
+	byName := make(map[string]models.Source, len(f.sources))
+	for _, source := range f.sources {
+		spec, ok := f.schedules[source.Name()]
+		if !ok {
+			spec = schedule.Spec{Interval: interval, MaxInterval: interval * schedule.DefaultBackoffFactor}
+		}
+		specs[source.Name()] = spec
+		byName[source.Name()] = source
+	}
+
+	scheduler := schedule.NewScheduler(specs, time.Now())
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	// Due sources are fetched in the background, so that a slow source does
+	// not hold back the others. A running source is claimed in the scheduler
+	// and is not started again before its run completes.
+	type runResult struct {
+		sources  []models.Source
+		outcomes map[string]schedule.Outcome
+		err      error
+	}
+	done := make(chan runResult)
+	running := 0
+
+	resetTimer(timer, scheduler, running)
 
 	for {
 		select {
-		case <-ticker.C:
-			if err := f.Run(ctx); err != nil {
-				log.Printf("Fetch run failed: %v", err)
+		case <-timer.C:
+			var due []models.Source
+			for _, name := range scheduler.Due(time.Now()) {
+				scheduler.Claim(name)
+				due = append(due, byName[name])
+			}
+
+			if len(due) > 0 {
+				running++
+				go func() {
+					outcomes, err := f.runSources(ctx, due)
+					done <- runResult{sources: due, outcomes: outcomes, err: err}
+				}()
+			}
+
+			resetTimer(timer, scheduler, running)
+		case result := <-done:
+			running--
+			if result.err != nil {
+				log.Printf("Fetch run failed: %v", result.err)
 			}
+
+			now := time.Now()
+			for _, source := range result.sources {
+				outcome, ok := result.outcomes[source.Name()]
+				if !ok {
+					outcome = schedule.OutcomeFailed
+				}
+				next := scheduler.Complete(source.Name(), now, outcome)
+				if next.IsZero() {
+					log.Printf("Source %s is not scheduled to run again", source.Name())
+					continue
+				}
+				log.Printf("Next fetch for source %s at %s", source.Name(), next.Format(time.RFC3339))
+			}
+
+			resetTimer(timer, scheduler, running)
 		case <-ctx.Done():
 			log.Println("Fetcher shutting down...")
+			for ; running > 0; running-- {
+				<-done
+			}
 			return nil
 		}
 	}
 }
 
+// resetTimer arms timer for the next scheduled run. Sources that are running
+// are scheduled when their run completes.
+func resetTimer(timer *time.Timer, scheduler *schedule.Scheduler, running int) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	next := scheduler.NextRun()
+	if next.IsZero() {
+		if running == 0 {
+			log.Println("No source is scheduled to run again")
+		}
+		return
+	}
+	timer.Reset(time.Until(next))
+}
+
 func (f *Fetcher) Vectorize(ctx context.Context, item *models.NewsItem) error {
-	vector, err := f.embedder.Vectorize(ctx, item.Title+" "+item.Description)
+	info, err := f.embedder.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get embedding model: %w", err)
+	}
+	vector, err := f.embedder.Vectorize(ctx, item.EmbeddingText())
 	if err != nil {
 		return fmt.Errorf("failed to vectorize item %s: %w", item.Guid, err)
 	}
 	item.Vector = vector
+	item.EmbeddingModel = info.Model
+	item.EmbeddingDim = len(vector)
 	return nil
 }
 
@@ -274,11 +841,10 @@ func (f *Fetcher) VectorizeWithRetry(ctx context.Context, item *models.NewsItem,
 		if err := f.Vectorize(ctx, item); err != nil {
 			lastErr = err
 			if isRetryableError(err) && attempt < maxRetries {
-				backoff := time.Duration(attempt*attempt) * time.Second
 				select {
 				case <-ctx.Done():
 					return ctx.Err()
-				case <-time.After(backoff):
+				case <-time.After(retryBackoff(attempt)):
 					continue
 				}
 			}
@@ -289,23 +855,72 @@ func (f *Fetcher) VectorizeWithRetry(ctx context.Context, item *models.NewsItem,
 	return fmt.Errorf("vectorization failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+func (f *Fetcher) VectorizeBatchWithRetry(ctx context.Context, items []embedding.BatchItem, maxRetries int) ([]embedding.BatchResult, error) {
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		results, err := f.embedder.VectorizeBatch(ctx, items)
+		if err != nil {
+			lastErr = err
+			if isRetryableError(err) && attempt < maxRetries {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(retryBackoff(attempt)):
+					continue
+				}
+			}
+			return nil, err
+		}
+		return results, nil
+	}
+	return nil, fmt.Errorf("batch vectorization failed after %d attempts: %w", maxRetries, lastErr)
+}
+
 func (f *Fetcher) AddNews(ctx context.Context, items *[]models.NewsItem, source string, lastParsed time.Time) error {
 	if err := f.storage.AddNews(ctx, items, source, lastParsed); err != nil {
 		return fmt.Errorf("failed to add news to storage: %w", err)
 	}
+	if err := f.storage.RefreshClusters(ctx, storage.ClusterIDs(*items)); err != nil {
+		return fmt.Errorf("failed to refresh story clusters: %w", err)
+	}
 	log.Printf("Added %d news items to storage from source %s", len(*items), source)
 	return nil
 }
 
+// isRetryableError reports whether the embedder failed transiently: it is
+// unavailable, overloaded or timed out. Rejected inputs are not retried.
 func isRetryableError(err error) bool {
-	// todo Add logic to determine if error is transient
-	// For now, assume network/timeout errors are retryable
-	return err != nil
+	return embedding.IsRetryable(err)
+}
+
+// retryBackoff is the delay before retry attempt+1: exponential from
+// retryBaseDelay up to retryMaxDelay with full jitter, so that workers hitting
+// the same outage do not retry in lockstep.
+func retryBackoff(attempt int) time.Duration {
+	backoff := retryBaseDelay << min(attempt-1, 10)
+	if backoff > retryMaxDelay {
+		backoff = retryMaxDelay
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
 }
 
 // GetStats returns a copy of the current fetch statistics
 func (f *Fetcher) GetStats() FetchStats {
 	f.stats.mu.RLock()
 	defer f.stats.mu.RUnlock()
-	return *f.stats
+	return FetchStats{
+		TotalSources:    f.stats.TotalSources,
+		SuccessfulFetch: f.stats.SuccessfulFetch,
+		FailedFetch:     f.stats.FailedFetch,
+		NotModified:     f.stats.NotModified,
+		Quarantined:     f.stats.Quarantined,
+		TotalItems:      f.stats.TotalItems,
+		SkippedItems:    f.stats.SkippedItems,
+		UpdatedItems:    f.stats.UpdatedItems,
+		DroppedItems:    f.stats.DroppedItems,
+		ExtractedItems:  f.stats.ExtractedItems,
+		VectorizedItems: f.stats.VectorizedItems,
+		PendingItems:    f.stats.PendingItems,
+		LastRunTime:     f.stats.LastRunTime,
+	}
 }
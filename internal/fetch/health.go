@@ -0,0 +1,76 @@
+package fetch
+
+import (
+	"newstrix/internal/models"
+	"time"
+)
+
+const (
+	DefaultQuarantineThreshold = 5
+	DefaultQuarantineBase      = 5 * time.Minute
+	DefaultQuarantineMax       = 6 * time.Hour
+
+	// healthSmoothing is the weight of the latest run in the moving averages.
+	healthSmoothing = 0.2
+)
+
+// HealthPolicy decides when a failing source is quarantined and for how long.
+type HealthPolicy struct {
+	// Threshold is the number of consecutive failures before quarantine.
+	Threshold int
+	// BaseDelay is the first quarantine period; it doubles with every
+	// further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		Threshold: DefaultQuarantineThreshold,
+		BaseDelay: DefaultQuarantineBase,
+		MaxDelay:  DefaultQuarantineMax,
+	}
+}
+
+func (p HealthPolicy) recordSuccess(h *models.SourceHealth, now time.Time, latency time.Duration, items int) {
+	p.recordRun(h, latency, items)
+	h.LastSuccessAt = &now
+	h.ConsecutiveFailures = 0
+	h.QuarantinedUntil = nil
+}
+
+func (p HealthPolicy) recordFailure(h *models.SourceHealth, now time.Time, latency time.Duration, err error) {
+	p.recordRun(h, latency, 0)
+	h.LastErrorAt = &now
+	h.LastError = err.Error()
+	h.ConsecutiveFailures++
+	h.TotalFailures++
+
+	if p.Threshold > 0 && h.ConsecutiveFailures >= p.Threshold {
+		until := now.Add(p.quarantineDelay(h.ConsecutiveFailures))
+		h.QuarantinedUntil = &until
+	}
+}
+
+func (p HealthPolicy) recordRun(h *models.SourceHealth, latency time.Duration, items int) {
+	latencyMs := float64(latency) / float64(time.Millisecond)
+	if h.TotalRuns == 0 {
+		h.AvgLatencyMs = latencyMs
+		h.AvgItemsPerRun = float64(items)
+	} else {
+		h.AvgLatencyMs += healthSmoothing * (latencyMs - h.AvgLatencyMs)
+		h.AvgItemsPerRun += healthSmoothing * (float64(items) - h.AvgItemsPerRun)
+	}
+	h.TotalRuns++
+}
+
+func (p HealthPolicy) quarantineDelay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for i := p.Threshold; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,160 @@
+package normalize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"golang.org/x/net/idna"
+	"net"
+	"net/url"
+	"newstrix/internal/models"
+	"strings"
+)
+
+const idPrefix = "urn:newstrix:"
+
+// trackingParams are query parameters that only identify the campaign or the
+// referrer and never change the article.
+var trackingParams = map[string]struct{}{
+	"fbclid":    {},
+	"gclid":     {},
+	"yclid":     {},
+	"ysclid":    {},
+	"dclid":     {},
+	"igshid":    {},
+	"mc_cid":    {},
+	"mc_eid":    {},
+	"_openstat": {},
+}
+
+const (
+	ReasonInvalidLink   = "invalid link"
+	ReasonDuplicateID   = "duplicate id"
+	ReasonDuplicateLink = "duplicate link"
+)
+
+// Issue describes an item that was dropped from a batch.
+type Issue struct {
+	Item   models.NewsItem
+	Reason string
+	// ConflictID is the id of the item that already owns the link or id.
+	ConflictID string
+}
+
+func (i Issue) String() string {
+	if i.ConflictID != "" {
+		return fmt.Sprintf("%s: %s (id %s, conflicts with %s)", i.Reason, i.Item.Link, i.Item.Guid, i.ConflictID)
+	}
+	return fmt.Sprintf("%s: %s (id %s)", i.Reason, i.Item.Link, i.Item.Guid)
+}
+
+// CanonicalURL lowercases scheme and host, drops default ports, the fragment
+// and tracking parameters, and sorts the remaining query parameters.
+func CanonicalURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme in %q", raw)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return "", fmt.Errorf("missing host in %q", raw)
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	u.Host = host
+
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "utm_") {
+		return true
+	}
+	_, ok := trackingParams[key]
+	return ok
+}
+
+// SynthesizeID derives a stable id for items whose feed has no GUID.
+func SynthesizeID(publisher string, canonicalLink string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(publisher) + "\n" + canonicalLink))
+	return idPrefix + hex.EncodeToString(sum[:16])
+}
+
+// ContentHash fingerprints what the feed says about an item. Only feed fields
+// are hashed so that a changed item can be detected without downloading the
+// article again.
+func ContentHash(item models.NewsItem) string {
+	normalized := strings.Join(strings.Fields(item.Title), " ") + "\n" + strings.Join(strings.Fields(item.Description), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Batch canonicalizes links, fills missing GUIDs and content hashes, and
+// drops items that would collide on the news id or link within the batch.
+func Batch(items []models.NewsItem) ([]models.NewsItem, []Issue) {
+	valid := make([]models.NewsItem, 0, len(items))
+	var issues []Issue
+
+	ids := make(map[string]string, len(items))
+	links := make(map[string]string, len(items))
+
+	for _, item := range items {
+		link, err := CanonicalURL(item.Link)
+		if err != nil {
+			issues = append(issues, Issue{Item: item, Reason: ReasonInvalidLink})
+			continue
+		}
+		item.Link = link
+		item.Title = strings.TrimSpace(item.Title)
+		item.Guid = strings.TrimSpace(item.Guid)
+		if item.Guid == "" {
+			item.Guid = SynthesizeID(item.Publisher, link)
+		}
+		item.ContentHash = ContentHash(item)
+
+		if owner, ok := ids[item.Guid]; ok {
+			issues = append(issues, Issue{Item: item, Reason: ReasonDuplicateID, ConflictID: owner})
+			continue
+		}
+		if owner, ok := links[item.Link]; ok {
+			issues = append(issues, Issue{Item: item, Reason: ReasonDuplicateLink, ConflictID: owner})
+			continue
+		}
+
+		ids[item.Guid] = item.Guid
+		links[item.Link] = item.Guid
+		valid = append(valid, item)
+	}
+
+	return valid, issues
+}
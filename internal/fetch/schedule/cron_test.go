@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 * * 1-5"},
+		{expr: "0,30 * 1,15 * *"},
+		{expr: "@daily"},
+		{expr: "@weekly"},
+		{expr: "0 0 * * 7"},
+		{expr: "0 0 29 2 *"},
+		// Day of week can still match when day of month never does.
+		{expr: "0 0 31 2 1"},
+		{expr: "* * * *", wantErr: true},
+		{expr: "* * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "a * * * *", wantErr: true},
+		{expr: "@never", wantErr: true},
+		{expr: "0 0 31 2 *", wantErr: true},
+		{expr: "0 0 30 2 *", wantErr: true},
+		{expr: "0 0 31 4,6,9,11 *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCron(%q) error = %v, want error %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(value string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{name: "every minute", expr: "* * * * *", from: "2025-10-17 10:07", want: "2025-10-17 10:08"},
+		{name: "strictly after", expr: "0 10 * * *", from: "2025-10-17 10:00", want: "2025-10-18 10:00"},
+		{name: "step", expr: "*/15 * * * *", from: "2025-10-17 10:07", want: "2025-10-17 10:15"},
+		{name: "next hour", expr: "*/15 * * * *", from: "2025-10-17 10:50", want: "2025-10-17 11:00"},
+		{name: "weekdays skip weekend", expr: "0 9 * * 1-5", from: "2025-10-17 18:00", want: "2025-10-20 09:00"},
+		{name: "sunday as 7", expr: "0 0 * * 7", from: "2025-10-13 12:00", want: "2025-10-19 00:00"},
+		{name: "sunday as 0", expr: "0 0 * * 0", from: "2025-10-13 12:00", want: "2025-10-19 00:00"},
+		{name: "day of month only", expr: "0 0 13 * *", from: "2025-10-01 00:00", want: "2025-10-13 00:00"},
+		{name: "day of month or week", expr: "0 0 13 * 5", from: "2025-10-01 00:00", want: "2025-10-03 00:00"},
+		{name: "month rollover", expr: "@monthly", from: "2025-12-15 08:00", want: "2026-01-01 00:00"},
+		{name: "leap day", expr: "0 0 29 2 *", from: "2025-03-01 00:00", want: "2028-02-29 00:00"},
+		{name: "31st skips short months", expr: "0 0 31 * *", from: "2025-09-01 00:00", want: "2025-10-31 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got, want := c.Next(at(tt.from)), at(tt.want); !got.Equal(want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.from, got, want)
+			}
+		})
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	// A Cron without any month never matches; ParseCron rejects such
+	// expressions, Next must still give up.
+	if got := (&Cron{}).Next(time.Now()); !got.IsZero() {
+		t.Fatalf("Next = %s, want zero time", got)
+	}
+}
@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DefaultBackoffFactor bounds adaptive backoff when a source has no explicit
+// max interval: an idle source is polled at most this many times less often.
+const DefaultBackoffFactor = 4
+
+// Spec describes when a single source is polled.
+type Spec struct {
+	// Interval between runs. Ignored when Cron is set.
+	Interval time.Duration
+	// Cron, when set, pins runs to cron ticks instead of a fixed interval.
+	Cron *Cron
+	// Jitter adds a random delay in [0, Jitter) to every run.
+	Jitter time.Duration
+	// MaxInterval caps adaptive backoff for idle sources. Backoff is
+	// disabled when it is not greater than Interval.
+	MaxInterval time.Duration
+}
+
+type Outcome int
+
+const (
+	// OutcomeNewItems resets backoff.
+	OutcomeNewItems Outcome = iota
+	// OutcomeIdle means the source answered but had nothing new.
+	OutcomeIdle
+	// OutcomeFailed keeps the current backoff unchanged.
+	OutcomeFailed
+)
+
+type entry struct {
+	spec     Spec
+	next     time.Time // zero when the source never runs again
+	idleRuns int
+}
+
+// Scheduler keeps the next run time of every source. It is not safe for
+// concurrent use.
+type Scheduler struct {
+	entries map[string]*entry
+	rnd     *rand.Rand
+}
+
+func NewScheduler(specs map[string]Spec, now time.Time) *Scheduler {
+	s := &Scheduler{
+		entries: make(map[string]*entry, len(specs)),
+		rnd:     rand.New(rand.NewSource(now.UnixNano())),
+	}
+
+	for name, spec := range specs {
+		next := now
+		if spec.Cron != nil {
+			next = spec.Cron.Next(now)
+		}
+		s.entries[name] = &entry{spec: spec, next: s.addJitter(next, spec)}
+	}
+
+	return s
+}
+
+// Due returns the sources whose next run is not after now, sorted by name.
+func (s *Scheduler) Due(now time.Time) []string {
+	var due []string
+	for name, e := range s.entries {
+		if !e.next.IsZero() && !e.next.After(now) {
+			due = append(due, name)
+		}
+	}
+	sort.Strings(due)
+	return due
+}
+
+// NextRun returns the earliest scheduled run across all sources, or the zero
+// time when no source runs again.
+func (s *Scheduler) NextRun() time.Time {
+	var next time.Time
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next
+}
+
+// Claim marks a source as running. It is neither due nor counted by NextRun
+// until Complete schedules its next run.
+func (s *Scheduler) Claim(name string) {
+	if e, ok := s.entries[name]; ok {
+		e.next = time.Time{}
+	}
+}
+
+// Complete schedules the next run of a source after a run finished at now. It
+// returns the zero time when the source does not run again.
+func (s *Scheduler) Complete(name string, now time.Time, outcome Outcome) time.Time {
+	e, ok := s.entries[name]
+	if !ok {
+		return time.Time{}
+	}
+
+	switch outcome {
+	case OutcomeNewItems:
+		e.idleRuns = 0
+	case OutcomeIdle:
+		e.idleRuns++
+	}
+
+	if e.spec.Cron != nil {
+		e.next = e.spec.Cron.Next(now)
+	} else {
+		e.next = now.Add(e.delay())
+	}
+	e.next = s.addJitter(e.next, e.spec)
+
+	return e.next
+}
+
+// delay doubles the interval for every idle run after the first one, up to
+// MaxInterval.
+func (e *entry) delay() time.Duration {
+	d := e.spec.Interval
+	if e.spec.MaxInterval <= d {
+		return d
+	}
+
+	for i := 1; i < e.idleRuns && d < e.spec.MaxInterval; i++ {
+		d *= 2
+	}
+	if d > e.spec.MaxInterval {
+		d = e.spec.MaxInterval
+	}
+	return d
+}
+
+// addJitter delays next by the jitter of spec. The zero time stays zero.
+func (s *Scheduler) addJitter(next time.Time, spec Spec) time.Time {
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.jitter(spec))
+}
+
+func (s *Scheduler) jitter(spec Spec) time.Duration {
+	if spec.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(s.rnd.Int63n(int64(spec.Jitter)))
+}
@@ -0,0 +1,140 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedulerBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     Spec
+		outcomes []Outcome
+		want     time.Duration
+	}{
+		{name: "new items", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeNewItems}, want: time.Minute},
+		{name: "first idle run", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeIdle}, want: time.Minute},
+		{name: "doubles", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeIdle}, want: 4 * time.Minute},
+		{name: "capped", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeIdle, OutcomeIdle, OutcomeIdle}, want: 10 * time.Minute},
+		{name: "reset by new items", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeIdle, OutcomeNewItems}, want: time.Minute},
+		{name: "kept on failure", spec: Spec{Interval: time.Minute, MaxInterval: 10 * time.Minute}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeFailed}, want: 2 * time.Minute},
+		{name: "disabled", spec: Spec{Interval: time.Minute}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeIdle}, want: time.Minute},
+		{name: "cron ignores backoff", spec: Spec{Cron: mustParseCron(t, "*/5 * * * *"), MaxInterval: time.Hour}, outcomes: []Outcome{OutcomeIdle, OutcomeIdle, OutcomeIdle}, want: 5 * time.Minute},
+	}
+
+	now := time.Date(2025, 10, 17, 10, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(map[string]Spec{"source": tt.spec}, now)
+			var next time.Time
+			for _, outcome := range tt.outcomes {
+				next = s.Complete("source", now, outcome)
+			}
+			if got := next.Sub(now); got != tt.want {
+				t.Fatalf("next run in %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerDue(t *testing.T) {
+	now := time.Date(2025, 10, 17, 10, 7, 0, 0, time.UTC)
+	s := NewScheduler(map[string]Spec{
+		"b":      {Interval: time.Hour},
+		"a":      {Interval: 30 * time.Minute},
+		"hourly": {Cron: mustParseCron(t, "0 * * * *")},
+	}, now)
+
+	if got, want := s.Due(now), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Due = %v, want %v", got, want)
+	}
+	if got := s.NextRun(); !got.Equal(now) {
+		t.Fatalf("NextRun = %s, want %s", got, now)
+	}
+
+	s.Complete("a", now, OutcomeNewItems)
+	s.Complete("b", now, OutcomeNewItems)
+	if got, want := s.NextRun(), now.Add(30*time.Minute); !got.Equal(want) {
+		t.Fatalf("NextRun = %s, want %s", got, want)
+	}
+	if got, want := s.Due(now.Add(53*time.Minute)), []string{"a", "hourly"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Due = %v, want %v", got, want)
+	}
+	if got := s.Complete("unknown", now, OutcomeIdle); !got.IsZero() {
+		t.Fatalf("Complete of unknown source = %s, want zero time", got)
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	now := time.Date(2025, 10, 17, 10, 0, 0, 0, time.UTC)
+	s := NewScheduler(map[string]Spec{"source": {Interval: time.Hour, Jitter: time.Minute}}, now)
+
+	for i := 0; i < 100; i++ {
+		next := s.Complete("source", now, OutcomeNewItems)
+		if delay := next.Sub(now); delay < time.Hour || delay >= time.Hour+time.Minute {
+			t.Fatalf("next run in %s, want [1h, 1h1m)", delay)
+		}
+	}
+}
+
+func mustParseCron(t *testing.T, expr string) *Cron {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", expr, err)
+	}
+	return c
+}
+
+func TestSchedulerNeverRuns(t *testing.T) {
+	now := time.Date(2025, 10, 17, 10, 0, 0, 0, time.UTC)
+	s := NewScheduler(map[string]Spec{
+		"never":  {Cron: &Cron{}, Jitter: time.Minute},
+		"hourly": {Interval: time.Hour},
+	}, now)
+
+	if got := s.Due(now.AddDate(10, 0, 0)); len(got) != 1 || got[0] != "hourly" {
+		t.Fatalf("Due = %v, want [hourly]", got)
+	}
+	if got := s.NextRun(); !got.Equal(now) {
+		t.Fatalf("NextRun = %s, want %s", got, now)
+	}
+	if got := s.Complete("never", now, OutcomeIdle); !got.IsZero() {
+		t.Fatalf("Complete = %s, want zero time", got)
+	}
+
+	s.Complete("hourly", now, OutcomeNewItems)
+	if got, want := s.NextRun(), now.Add(time.Hour); !got.Equal(want) {
+		t.Fatalf("NextRun = %s, want %s", got, want)
+	}
+
+	only := NewScheduler(map[string]Spec{"never": {Cron: &Cron{}}}, now)
+	if got := only.NextRun(); !got.IsZero() {
+		t.Fatalf("NextRun = %s, want zero time", got)
+	}
+}
+
+func TestSchedulerClaim(t *testing.T) {
+	now := time.Date(2025, 10, 17, 10, 0, 0, 0, time.UTC)
+	s := NewScheduler(map[string]Spec{
+		"slow": {Interval: time.Minute},
+		"fast": {Interval: 5 * time.Minute},
+	}, now)
+
+	s.Claim("slow")
+	if got := s.Due(now.Add(time.Hour)); len(got) != 1 || got[0] != "fast" {
+		t.Fatalf("Due = %v, want [fast]", got)
+	}
+
+	s.Claim("fast")
+	if got := s.NextRun(); !got.IsZero() {
+		t.Fatalf("NextRun = %s, want zero time", got)
+	}
+
+	done := now.Add(10 * time.Minute)
+	s.Complete("slow", done, OutcomeNewItems)
+	if got, want := s.NextRun(), done.Add(time.Minute); !got.Equal(want) {
+		t.Fatalf("NextRun = %s, want %s", got, want)
+	}
+}
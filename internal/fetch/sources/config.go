@@ -0,0 +1,220 @@
+package sources
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"newstrix/internal/fetch/extract"
+	"newstrix/internal/fetch/schedule"
+	"newstrix/internal/models"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultType = "rss"
+
+// SourceConfig is a single entry of the sources file.
+type SourceConfig struct {
+	Name      string            `yaml:"name"`
+	Type      string            `yaml:"type"`
+	URL       string            `yaml:"url"`
+	Publisher string            `yaml:"publisher"`
+	Enabled   *bool             `yaml:"enabled"`
+	Options   map[string]string `yaml:"options"`
+
+	// Interval, Schedule, Jitter and MaxInterval control polling, see
+	// schedule.Spec. Durations use time.ParseDuration syntax.
+	Interval    string `yaml:"interval"`
+	Schedule    string `yaml:"schedule"`
+	Jitter      string `yaml:"jitter"`
+	MaxInterval string `yaml:"max_interval"`
+}
+
+func (c SourceConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// ExtractRule returns the full text extraction override for the source, taken
+// from the extract_selector and full_text options.
+func (c SourceConfig) ExtractRule() (extract.Rule, bool, error) {
+	selector, hasSelector := c.Options["extract_selector"]
+	fullText, hasFullText := c.Options["full_text"]
+	if !hasSelector && !hasFullText {
+		return extract.Rule{}, false, nil
+	}
+
+	rule := extract.Rule{Selector: selector}
+	if hasFullText {
+		enabled, err := strconv.ParseBool(fullText)
+		if err != nil {
+			return rule, false, fmt.Errorf("source %s: invalid full_text %q", c.Name, fullText)
+		}
+		rule.Disabled = !enabled
+	}
+	return rule, true, nil
+}
+
+// ScheduleSpec returns the polling schedule of the source. Sources without an
+// interval or cron schedule are polled every defaultInterval.
+func (c SourceConfig) ScheduleSpec(defaultInterval time.Duration) (schedule.Spec, error) {
+	spec := schedule.Spec{Interval: defaultInterval}
+
+	var err error
+	if c.Interval != "" {
+		if spec.Interval, err = parsePositiveDuration(c.Interval); err != nil {
+			return spec, fmt.Errorf("source %s: invalid interval: %w", c.Name, err)
+		}
+	}
+	if c.Schedule != "" {
+		if spec.Cron, err = schedule.ParseCron(c.Schedule); err != nil {
+			return spec, fmt.Errorf("source %s: invalid schedule: %w", c.Name, err)
+		}
+	}
+	if c.Jitter != "" {
+		if spec.Jitter, err = parsePositiveDuration(c.Jitter); err != nil {
+			return spec, fmt.Errorf("source %s: invalid jitter: %w", c.Name, err)
+		}
+	}
+
+	spec.MaxInterval = spec.Interval * schedule.DefaultBackoffFactor
+	if c.MaxInterval != "" {
+		if spec.MaxInterval, err = parsePositiveDuration(c.MaxInterval); err != nil {
+			return spec, fmt.Errorf("source %s: invalid max_interval: %w", c.Name, err)
+		}
+	}
+
+	return spec, nil
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %s must be positive", value)
+	}
+	return d, nil
+}
+
+type sourcesFile struct {
+	Sources []SourceConfig `yaml:"sources"`
+}
+
+// Factory builds a source from its config. Custom adapters register their
+// own factory under a new type name and should use the shared client for any
+// HTTP requests.
+type Factory func(cfg SourceConfig, client *http.Client) (models.Source, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		"rss":  newFeedSource,
+		"atom": newFeedSource,
+	}
+)
+
+func Register(kind string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[kind] = factory
+}
+
+func newFeedSource(cfg SourceConfig, client *http.Client) (models.Source, error) {
+	return NewFeed(cfg, client)
+}
+
+func LoadConfig(path string) ([]SourceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sources file %s: %w", path, err)
+	}
+
+	var file sourcesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse sources file %s: %w", path, err)
+	}
+
+	seen := make(map[string]struct{}, len(file.Sources))
+	for i := range file.Sources {
+		cfg := &file.Sources[i]
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("sources file %s: entry %d has no name", path, i)
+		}
+		if _, ok := seen[cfg.Name]; ok {
+			return nil, fmt.Errorf("sources file %s: duplicate source name %s", path, cfg.Name)
+		}
+		seen[cfg.Name] = struct{}{}
+
+		if cfg.Type == "" {
+			cfg.Type = DefaultType
+		}
+		if cfg.Publisher == "" {
+			cfg.Publisher = cfg.Name
+		}
+	}
+
+	return file.Sources, nil
+}
+
+// ExtractRules collects per-publisher extraction overrides of enabled sources.
+func ExtractRules(cfgs []SourceConfig) (map[string]extract.Rule, error) {
+	rules := make(map[string]extract.Rule)
+	for _, cfg := range cfgs {
+		if !cfg.IsEnabled() {
+			continue
+		}
+		rule, ok, err := cfg.ExtractRule()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rules[cfg.Publisher] = rule
+		}
+	}
+	return rules, nil
+}
+
+// Schedules returns the polling schedule of every enabled source by name.
+func Schedules(cfgs []SourceConfig, defaultInterval time.Duration) (map[string]schedule.Spec, error) {
+	specs := make(map[string]schedule.Spec)
+	for _, cfg := range cfgs {
+		if !cfg.IsEnabled() {
+			continue
+		}
+		spec, err := cfg.ScheduleSpec(defaultInterval)
+		if err != nil {
+			return nil, err
+		}
+		specs[cfg.Name] = spec
+	}
+	return specs, nil
+}
+
+// Build creates sources for all enabled entries.
+func Build(cfgs []SourceConfig, client *http.Client) ([]models.Source, error) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	var srcs []models.Source
+	for _, cfg := range cfgs {
+		if !cfg.IsEnabled() {
+			continue
+		}
+
+		factory, ok := factories[cfg.Type]
+		if !ok {
+			return nil, fmt.Errorf("source %s: unknown type %s", cfg.Name, cfg.Type)
+		}
+
+		src, err := factory(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, src)
+	}
+
+	return srcs, nil
+}
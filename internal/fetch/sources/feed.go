@@ -0,0 +1,165 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mmcdole/gofeed"
+	"net/http"
+	"newstrix/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feed is a generic RSS/Atom source described entirely by its SourceConfig.
+type Feed struct {
+	name       string
+	url        string
+	publisher  string
+	userAgent  string
+	timeout    time.Duration
+	maxItems   int
+	categories map[string]struct{}
+	client     *http.Client
+	parser     *gofeed.Parser
+}
+
+func NewFeed(cfg SourceConfig, client *http.Client) (*Feed, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("source %s: url is required", cfg.Name)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	f := &Feed{
+		name:      cfg.Name,
+		url:       cfg.URL,
+		publisher: cfg.Publisher,
+		userAgent: cfg.Options["user_agent"],
+		client:    client,
+		parser:    gofeed.NewParser(),
+	}
+
+	if v, ok := cfg.Options["max_items"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("source %s: invalid max_items option %q", cfg.Name, v)
+		}
+		f.maxItems = n
+	}
+
+	if v, ok := cfg.Options["timeout"]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("source %s: invalid timeout option %q", cfg.Name, v)
+		}
+		f.timeout = d
+	}
+
+	if v, ok := cfg.Options["categories"]; ok && v != "" {
+		f.categories = make(map[string]struct{})
+		for _, c := range strings.Split(v, ",") {
+			f.categories[strings.ToLower(strings.TrimSpace(c))] = struct{}{}
+		}
+	}
+
+	return f, nil
+}
+
+func (f *Feed) Name() string {
+	return f.name
+}
+
+func (f *Feed) Fetch(ctx context.Context, timeline time.Time) (*[]models.NewsItem, error) {
+	items, _, err := f.FetchConditional(ctx, timeline, models.CacheValidators{})
+	if errors.Is(err, models.ErrNotModified) {
+		return &[]models.NewsItem{}, nil
+	}
+	return items, err
+}
+
+// FetchConditional downloads the feed only if it changed since the response
+// the validators were taken from.
+func (f *Feed) FetchConditional(ctx context.Context, timeline time.Time, validators models.CacheValidators) (*[]models.NewsItem, models.CacheValidators, error) {
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, validators, err
+	}
+	if f.userAgent != "" {
+		req.Header.Set("User-Agent", f.userAgent)
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, validators, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, models.ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, validators, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, f.url)
+	}
+
+	feed, err := f.parser.Parse(resp.Body)
+	if err != nil {
+		return nil, validators, err
+	}
+
+	next := models.CacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	var items []models.NewsItem
+	for _, entry := range feed.Items {
+		if entry.PublishedParsed == nil || entry.PublishedParsed.Before(timeline) {
+			continue
+		}
+		if !f.matchCategories(entry.Categories) {
+			continue
+		}
+
+		items = append(items, models.NewsItem{
+			Guid:        entry.GUID,
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Description,
+			PublishedAt: *entry.PublishedParsed,
+			Publisher:   f.publisher,
+		})
+
+		if f.maxItems > 0 && len(items) >= f.maxItems {
+			break
+		}
+	}
+
+	return &items, next, nil
+}
+
+func (f *Feed) matchCategories(categories []string) bool {
+	if len(f.categories) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		if _, ok := f.categories[strings.ToLower(strings.TrimSpace(c))]; ok {
+			return true
+		}
+	}
+	return false
+}
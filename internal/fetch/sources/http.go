@@ -0,0 +1,108 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	DefaultHTTPTimeout = 30 * time.Second
+	DefaultUserAgent   = "Newstrix/1.0 (+https://github.com/whyowl/newstrix)"
+	DefaultMaxBodySize = 10 << 20
+)
+
+var ErrBodyTooLarge = errors.New("response body exceeds size limit")
+
+// HTTPConfig configures the client shared by all sources and the full text
+// extractor.
+type HTTPConfig struct {
+	Timeout     time.Duration
+	UserAgent   string
+	ProxyURL    string
+	MaxBodySize int64
+}
+
+func NewHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHTTPTimeout
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %s: %w", cfg.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &limitedTransport{
+			base:        transport,
+			userAgent:   cfg.UserAgent,
+			maxBodySize: cfg.MaxBodySize,
+		},
+	}, nil
+}
+
+// limitedTransport sets the default User-Agent and refuses to read response
+// bodies larger than maxBodySize.
+type limitedTransport struct {
+	base        http.RoundTripper
+	userAgent   string
+	maxBodySize int64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentLength > t.maxBodySize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", req.URL, ErrBodyTooLarge)
+	}
+
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.maxBodySize}
+	return resp, nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Only fail if there is actually more data behind the limit.
+		var probe [1]byte
+		if n, _ := b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
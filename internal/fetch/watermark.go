@@ -0,0 +1,55 @@
+package fetch
+
+import (
+	"newstrix/internal/models"
+	"time"
+)
+
+const DefaultWatermarkOverlap = 10 * time.Minute
+
+// sourceBatch collects everything a single run learned about one source.
+type sourceBatch struct {
+	lastParsed time.Time
+	knownUntil time.Time
+	validators *models.CacheValidators
+	// stored holds every item to store, including pending ones that could
+	// not be vectorized yet.
+	stored  []models.NewsItem
+	pending int
+}
+
+// watermark returns the next high-watermark for the source: the newest
+// publish time that is stored. Items that could not be vectorized are stored
+// as pending, so they do not hold the watermark back.
+func (b *sourceBatch) watermark() time.Time {
+	next := b.lastParsed
+	if b.knownUntil.After(next) {
+		next = b.knownUntil
+	}
+	for _, item := range b.stored {
+		if item.PublishedAt.After(next) {
+			next = item.PublishedAt
+		}
+	}
+
+	return next
+}
+
+// since returns the lower bound passed to Source.Fetch. It reaches back by the
+// overlap window to pick up items published with a backdated timestamp.
+func since(lastParsed time.Time, overlap time.Duration) time.Time {
+	if lastParsed.IsZero() || overlap <= 0 {
+		return lastParsed
+	}
+	return lastParsed.Add(-overlap)
+}
+
+func newestPublished(items []models.NewsItem) time.Time {
+	var newest time.Time
+	for _, item := range items {
+		if item.PublishedAt.After(newest) {
+			newest = item.PublishedAt
+		}
+	}
+	return newest
+}
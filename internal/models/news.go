@@ -2,29 +2,206 @@ package models
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
 	"time"
 )
 
+// MaxEmbeddingTextLength caps how much article text is sent to the embedder.
+const MaxEmbeddingTextLength = 4000
+
 type Source interface {
 	Name() string
 	Fetch(ctx context.Context, timeline time.Time) (*[]NewsItem, error)
 }
 
+// ErrNotModified is returned by ConditionalSource when the feed did not change
+// since the response the validators were taken from.
+var ErrNotModified = errors.New("source not modified")
+
+// CacheValidators are the HTTP validators remembered between fetches.
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// ConditionalSource is implemented by sources that support conditional
+// requests. The fetcher persists the returned validators per source.
+type ConditionalSource interface {
+	Source
+	FetchConditional(ctx context.Context, timeline time.Time, validators CacheValidators) (*[]NewsItem, CacheValidators, error)
+}
+
 type NewsItem struct {
 	Guid        string    `json:"id"`
 	Title       string    `json:"title"`
 	Link        string    `json:"link"`
 	Description string    `json:"description"`
+	FullText    string    `json:"full_text,omitempty"`
 	PublishedAt time.Time `json:"published_at"`
 	Publisher   string    `json:"publisher"`
+	ClusterID   string    `json:"cluster_id,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	Revision    int       `json:"revision,omitempty"`
 	Vector      []float32 `json:"-"`
+	// EmbeddingModel names the model that produced Vector; vectors of
+	// different models are not comparable.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	EmbeddingDim   int    `json:"-"`
+	// EmbeddingStatus is EmbeddingPending while the item waits for a vector.
+	EmbeddingStatus string `json:"embedding_status,omitempty"`
+	// EmbeddingError is why the last attempt to vectorize the item failed.
+	EmbeddingError string `json:"-"`
+	// Chunks are the passages of the article body, embedded separately.
+	Chunks []NewsChunk `json:"-"`
+	// Passage is the best-matching chunk when searching by chunks.
+	Passage string `json:"passage,omitempty"`
+	// Score is what a search ordered the item by: the distance to the query
+	// vector, or the full-text rank.
+	Score float64 `json:"-"`
+}
+
+const (
+	EmbeddingPending = "pending"
+	EmbeddingReady   = "ready"
+)
+
+// NewsChunk is one passage of an article with its own vector.
+type NewsChunk struct {
+	NewsID         string
+	Index          int
+	Text           string
+	Vector         []float32
+	EmbeddingModel string
+	EmbeddingDim   int
+}
+
+// EmbeddingText returns the text used to vectorize the chunk. The title is
+// prepended so that passages keep the subject of the article.
+func (c *NewsChunk) EmbeddingText(title string) string {
+	return strings.TrimSpace(title + "\n" + c.Text)
+}
+
+// NewsRevision is one version of an item. Superseded versions carry the time
+// they were replaced; the current version has none.
+type NewsRevision struct {
+	Revision     int        `json:"revision"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	FullText     string     `json:"full_text,omitempty"`
+	ContentHash  string     `json:"content_hash,omitempty"`
+	PublishedAt  time.Time  `json:"published_at"`
+	SupersededAt *time.Time `json:"superseded_at,omitempty"`
+	Current      bool       `json:"current"`
+}
+
+// EmbeddingText returns the text used to vectorize the item: the title with
+// the full article body when it was extracted, or the feed teaser otherwise.
+func (n *NewsItem) EmbeddingText() string {
+	body := n.Description
+	if n.FullText != "" {
+		body = n.FullText
+	}
+
+	text := strings.TrimSpace(n.Title + " " + body)
+	if runes := []rune(text); len(runes) > MaxEmbeddingTextLength {
+		text = string(runes[:MaxEmbeddingTextLength])
+	}
+	return text
+}
+
+// ReembedProgress is the position of a re-embedding run towards Model. Rows
+// are walked by id, LastID is the last one handled.
+type ReembedProgress struct {
+	Model     string
+	LastID    string
+	Processed int
+	Failed    int
+	StartedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	// SearchModeVector ranks items by the vector of the whole item.
+	SearchModeVector = "vector"
+	// SearchModeChunks ranks items by their best-matching chunk.
+	SearchModeChunks = "chunks"
+	// SearchModeHybrid fuses full-text and vector rankings of the query.
+	SearchModeHybrid = "hybrid"
+)
+
+// How vectors are compared. bge-m3 vectors are unit length, so cosine and
+// inner product rank the same and L2 only differs in scale.
+const (
+	// MetricCosine scores by cosine similarity, in [-1, 1].
+	MetricCosine = "cosine"
+	// MetricInnerProduct scores by the inner product of the vectors.
+	MetricInnerProduct = "inner_product"
+	// MetricL2 scores by 1 / (1 + euclidean distance), in (0, 1].
+	MetricL2 = "l2"
+)
+
+// Similarity converts a distance between vectors under metric, as computed by
+// pgvector, to its score: higher is more similar.
+func Similarity(metric string, distance float64) float64 {
+	switch metric {
+	case MetricInnerProduct:
+		// pgvector returns the negative inner product.
+		return -distance
+	case MetricL2:
+		return 1 / (1 + distance)
+	default:
+		return 1 - distance
+	}
+}
+
+// MaxDistance is the largest distance under metric whose Similarity is at
+// least score. It is +Inf when every distance is.
+func MaxDistance(metric string, score float64) float64 {
+	switch metric {
+	case MetricInnerProduct:
+		return -score
+	case MetricL2:
+		if score <= 0 {
+			return math.Inf(1)
+		}
+		return 1/score - 1
+	default:
+		return 1 - score
+	}
 }
 
 type SearchParams struct {
+	Mode string
+	// Text is the query as typed, matched by full-text search.
+	Text     string
 	Keywords *[]string
 	Vector   *[]float32
-	Source   *string
-	From     *time.Time
-	To       *time.Time
-	Limit    int
+	// Model restricts vector search to items embedded by the same model as
+	// Vector.
+	Model  string
+	Source *string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	// Metric compares Vector with the stored vectors, MetricCosine if empty.
+	Metric string
+	// MinScore drops vector hits scoring below it.
+	MinScore *float64
+	// After continues the search behind the hit with this sort key.
+	After *SortKey
+}
+
+// SortKey is the position of a hit in search order. Score is compared for
+// vector and full-text search, PublishedAt for filter search; ID breaks ties.
+type SortKey struct {
+	PublishedAt time.Time
+	Score       float64
+	ID          string
+}
+
+// Key returns the position of n in search order.
+func (n *NewsItem) Key() SortKey {
+	return SortKey{PublishedAt: n.PublishedAt, Score: n.Score, ID: n.Guid}
 }
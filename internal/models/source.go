@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	SourceStatusOK          = "ok"
+	SourceStatusFailing     = "failing"
+	SourceStatusQuarantined = "quarantined"
+	SourceStatusUnknown     = "unknown"
+)
+
+// SourceHealth is the persisted health record of a source, updated after
+// every fetch attempt.
+type SourceHealth struct {
+	Source              string     `json:"source"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalRuns           int        `json:"total_runs"`
+	TotalFailures       int        `json:"total_failures"`
+	AvgLatencyMs        float64    `json:"avg_latency_ms"`
+	AvgItemsPerRun      float64    `json:"avg_items_per_run"`
+	QuarantinedUntil    *time.Time `json:"quarantined_until,omitempty"`
+}
+
+func (h *SourceHealth) Quarantined(now time.Time) bool {
+	return h.QuarantinedUntil != nil && now.Before(*h.QuarantinedUntil)
+}
+
+func (h *SourceHealth) Status(now time.Time) string {
+	switch {
+	case h.Quarantined(now):
+		return SourceStatusQuarantined
+	case h.ConsecutiveFailures > 0:
+		return SourceStatusFailing
+	case h.LastSuccessAt != nil:
+		return SourceStatusOK
+	default:
+		return SourceStatusUnknown
+	}
+}
@@ -0,0 +1,170 @@
+package reembed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"newstrix/internal/chunk"
+	"newstrix/internal/embedding"
+	"newstrix/internal/models"
+	"time"
+)
+
+const DefaultBatchSize = 64
+
+type Repository interface {
+	CountToReembed(ctx context.Context, model string) (int, error)
+	NewsToReembed(ctx context.Context, model string, after string, limit int) ([]models.NewsItem, error)
+	SaveReembedBatch(ctx context.Context, items []models.NewsItem, progress models.ReembedProgress) (models.ReembedProgress, error)
+	GetReembedProgress(ctx context.Context, model string) (*models.ReembedProgress, error)
+	DeleteReembedProgress(ctx context.Context, model string) error
+}
+
+type Vectorizer interface {
+	Info(ctx context.Context) (embedding.ModelInfo, error)
+	VectorizeBatch(ctx context.Context, items []embedding.BatchItem) ([]embedding.BatchResult, error)
+}
+
+// Job moves every stored item to the model currently served by the embedder.
+// Items are walked in id order and the position is saved after each batch, so
+// an interrupted run resumes where it stopped. Items that fail keep their old
+// vector and are retried by the next run.
+type Job struct {
+	repository Repository
+	embedder   Vectorizer
+	chunker    *chunk.Chunker
+	batchSize  int
+}
+
+// NewJob creates a job that reads batchSize items at a time and sends at most
+// batchSize texts per embedding call. With a chunker the chunks of every item
+// are rebuilt as well.
+func NewJob(repository Repository, embedder Vectorizer, chunker *chunk.Chunker, batchSize int) *Job {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Job{
+		repository: repository,
+		embedder:   embedder,
+		chunker:    chunker,
+		batchSize:  batchSize,
+	}
+}
+
+// Reset forgets the saved position of the run towards the current model.
+func (j *Job) Reset(ctx context.Context) error {
+	info, err := j.embedder.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get embedding model: %w", err)
+	}
+	return j.repository.DeleteReembedProgress(ctx, info.Model)
+}
+
+func (j *Job) Run(ctx context.Context) error {
+	info, err := j.embedder.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get embedding model: %w", err)
+	}
+
+	progress, err := j.repository.GetReembedProgress(ctx, info.Model)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &models.ReembedProgress{Model: info.Model, StartedAt: time.Now()}
+	} else {
+		log.Printf("Resuming re-embedding to %s after id %q: %d processed, %d failed", info.Model, progress.LastID, progress.Processed, progress.Failed)
+	}
+
+	remaining, err := j.repository.CountToReembed(ctx, info.Model)
+	if err != nil {
+		return err
+	}
+	// Items that failed before the saved position are still counted as
+	// remaining, so the total is an upper bound.
+	total := progress.Processed + remaining
+	log.Printf("Re-embedding up to %d items to %s (%d dimensions)", remaining, info.Model, info.Dimension)
+
+	for {
+		items, err := j.repository.NewsToReembed(ctx, info.Model, progress.LastID, j.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		vectorized, failed, err := j.vectorize(ctx, items, info)
+		if err != nil {
+			return err
+		}
+
+		progress.LastID = items[len(items)-1].Guid
+		progress.Failed += failed
+		saved, err := j.repository.SaveReembedBatch(ctx, vectorized, *progress)
+		if err != nil {
+			return err
+		}
+		if stale := len(vectorized) - (saved.Processed - progress.Processed); stale > 0 {
+			log.Printf("Skipped %d items that were revised while being re-embedded, they are retried next run", stale)
+		}
+		*progress = saved
+
+		log.Printf("Re-embedded %d/%d items to %s (%.1f%%), %d failed",
+			progress.Processed, total, info.Model, percent(progress.Processed, total), progress.Failed)
+	}
+
+	log.Printf("Re-embedding to %s finished in %s: %d items, %d failed",
+		info.Model, time.Since(progress.StartedAt).Round(time.Second), progress.Processed, progress.Failed)
+
+	// A finished run starts over next time so that failed items are retried.
+	return j.repository.DeleteReembedProgress(ctx, info.Model)
+}
+
+// vectorize returns the items that got all vectors of the model in info,
+// including their chunks when a chunker is set, and the number of items that
+// failed. An error means a batch could not be sent at all and the run should
+// stop.
+func (j *Job) vectorize(ctx context.Context, items []models.NewsItem, info embedding.ModelInfo) ([]models.NewsItem, int, error) {
+	var requests []embedding.BatchItem
+	for i := range items {
+		items[i].Chunks = nil
+		if j.chunker != nil {
+			items[i].Chunks = j.chunker.Chunks(items[i])
+		}
+		requests = append(requests, embedding.ItemRequests(items[i])...)
+	}
+
+	byID := make(map[string]embedding.BatchResult, len(requests))
+	for start := 0; start < len(requests); start += j.batchSize {
+		results, err := j.embedder.VectorizeBatch(ctx, requests[start:min(start+j.batchSize, len(requests))])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to vectorize batch: %w", err)
+		}
+		for _, result := range results {
+			if result.Err == nil && len(result.Vector) != info.Dimension {
+				return nil, 0, fmt.Errorf("embedding model changed during the run: got %d dimensions, %s has %d", len(result.Vector), info.Model, info.Dimension)
+			}
+			byID[result.ID] = result
+		}
+	}
+
+	var vectorized []models.NewsItem
+	failed := 0
+	for _, item := range items {
+		if err := embedding.ApplyVectors(&item, byID, info); err != nil {
+			log.Printf("Failed to re-embed item %s: %v", item.Guid, err)
+			failed++
+			continue
+		}
+		vectorized = append(vectorized, item)
+	}
+	return vectorized, failed, nil
+}
+
+func percent(done, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(done) / float64(total)
+}
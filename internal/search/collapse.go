@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"newstrix/internal/models"
+)
+
+// CollapseFactor is how many more hits are requested from storage when
+// collapsing, so that the page is still full after duplicates are folded.
+const CollapseFactor = 3
+
+// CollapsedItem is the best hit of a story cluster together with the other
+// publishers that reported the same story.
+type CollapsedItem struct {
+	SearchResult
+	OtherSources    int      `json:"other_sources"`
+	OtherPublishers []string `json:"other_publishers,omitempty"`
+}
+
+// CollapsedPage is one page of collapsed hits. Clusters are folded within a
+// page; a story may appear again on a later page.
+type CollapsedPage struct {
+	Items []CollapsedItem
+	PageInfo
+}
+
+func (s *SearchEngine) searchCollapsed(ctx context.Context, request models.SearchParams, fp, token string) (*CollapsedPage, error) {
+	limit := request.Limit
+	raw := limit * CollapseFactor
+
+	hits, err := s.fetch(ctx, request, raw+1, fp, token)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CollapsedPage{PageInfo: pageInfo(request, true)}
+	collapsed, consumed, err := s.collapse(ctx, hits.items[:min(raw, len(hits.items))], limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(collapsed))
+	for _, item := range collapsed {
+		ids = append(ids, item.Guid)
+	}
+	matched, err := s.matchFields(ctx, request, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range collapsed {
+		collapsed[i].SearchResult = newResult(collapsed[i].NewsItem, hits.start.Rank+i+1, page.PageInfo, matched[collapsed[i].Guid])
+	}
+
+	page.Items = collapsed
+	if page.Items == nil {
+		page.Items = []CollapsedItem{}
+	}
+	if consumed > 0 && consumed < len(hits.items) {
+		page.Next = s.cursors.encode(hits.after(consumed-1, hits.start.Rank+len(collapsed)))
+	}
+	return page, nil
+}
+
+// collapse keeps the first (best ranked) hit of every cluster in order. It
+// also returns how many items it looked at.
+func (s *SearchEngine) collapse(ctx context.Context, items []models.NewsItem, limit int) ([]CollapsedItem, int, error) {
+	seen := make(map[string]struct{}, len(items))
+	var collapsed []CollapsedItem
+	var clusterIDs []string
+
+	consumed := 0
+	for _, item := range items {
+		consumed++
+		key := item.ClusterID
+		if key == "" {
+			key = item.Guid
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		collapsed = append(collapsed, CollapsedItem{SearchResult: SearchResult{NewsItem: item}})
+		if item.ClusterID != "" {
+			clusterIDs = append(clusterIDs, item.ClusterID)
+		}
+		if len(collapsed) == limit {
+			break
+		}
+	}
+
+	if len(clusterIDs) == 0 {
+		return collapsed, consumed, nil
+	}
+
+	publishers, err := s.storage.ClusterPublishers(ctx, clusterIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range collapsed {
+		for _, publisher := range publishers[collapsed[i].ClusterID] {
+			if publisher != collapsed[i].Publisher {
+				collapsed[i].OtherPublishers = append(collapsed[i].OtherPublishers, publisher)
+			}
+		}
+		collapsed[i].OtherSources = len(collapsed[i].OtherPublishers)
+	}
+
+	return collapsed, consumed, nil
+}
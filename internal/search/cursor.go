@@ -0,0 +1,95 @@
+package search
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"newstrix/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for cursors that were tampered with or belong
+// to another search.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the position after the last hit of a page. Hybrid search has no
+// sort key and continues at Offset, the number of hits before the cursor,
+// instead.
+type cursor struct {
+	// Query is the fingerprint of the search the cursor belongs to.
+	Query       string    `json:"q"`
+	PublishedAt time.Time `json:"t,omitempty"`
+	Score       float64   `json:"s,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	Offset      int       `json:"o,omitempty"`
+	// Rank is the number of results shown before the cursor.
+	Rank int `json:"r,omitempty"`
+}
+
+func (c cursor) key() *models.SortKey {
+	return &models.SortKey{PublishedAt: c.PublishedAt, Score: c.Score, ID: c.ID}
+}
+
+// cursorCodec turns cursors into opaque tokens signed with HMAC-SHA256, so
+// that clients cannot forge positions.
+type cursorCodec struct {
+	secret []byte
+}
+
+func newCursorCodec(secret []byte) *cursorCodec {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("generate cursor secret: %v", err))
+		}
+	}
+	return &cursorCodec{secret: secret}
+}
+
+func (c *cursorCodec) encode(cur cursor) string {
+	payload, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// decode verifies token and checks that it was issued for the search with
+// the given fingerprint.
+func (c *cursorCodec) decode(token, query string) (cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor{}, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(payload, &cur); err != nil || cur.Query != query {
+		return cursor{}, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+func (c *cursorCodec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// fingerprint identifies a search by everything that affects its order and
+// filters, but not by its page size.
+func fingerprint(parts ...interface{}) string {
+	data, _ := json.Marshal(parts)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := newCursorCodec([]byte("secret"))
+	tests := []struct {
+		name string
+		cur  cursor
+	}{
+		{name: "filters", cur: cursor{Query: "fp", PublishedAt: time.Date(2025, 10, 17, 10, 0, 0, 0, time.UTC), ID: "a", Rank: 20}},
+		{name: "vector", cur: cursor{Query: "fp", Score: 0.123456789, ID: "b", Rank: 40}},
+		{name: "hybrid", cur: cursor{Query: "fp", Offset: 60, Rank: 60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.decode(codec.encode(tt.cur), tt.cur.Query)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !got.PublishedAt.Equal(tt.cur.PublishedAt) || got.Score != tt.cur.Score || got.ID != tt.cur.ID ||
+				got.Offset != tt.cur.Offset || got.Rank != tt.cur.Rank || got.Query != tt.cur.Query {
+				t.Fatalf("decode = %+v, want %+v", got, tt.cur)
+			}
+		})
+	}
+}
+
+func TestCursorRejected(t *testing.T) {
+	codec := newCursorCodec([]byte("secret"))
+	token := codec.encode(cursor{Query: "fp", Score: 0.5, ID: "a", Rank: 20})
+	payload, signature, _ := strings.Cut(token, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"q":"fp","s":0.1,"id":"a","r":20}`))
+
+	tests := []struct {
+		name  string
+		token string
+		query string
+	}{
+		{name: "empty", token: "", query: "fp"},
+		{name: "no signature", token: payload, query: "fp"},
+		{name: "bad payload encoding", token: "!!!." + signature, query: "fp"},
+		{name: "bad signature encoding", token: payload + ".!!!", query: "fp"},
+		{name: "tampered payload", token: forged + "." + signature, query: "fp"},
+		{name: "tampered signature", token: payload + "." + flipFirst(signature), query: "fp"},
+		{name: "truncated signature", token: payload + "." + signature[:len(signature)/2], query: "fp"},
+		{name: "other secret", token: newCursorCodec([]byte("other")).encode(cursor{Query: "fp", ID: "a"}), query: "fp"},
+		{name: "random secret", token: newCursorCodec(nil).encode(cursor{Query: "fp", ID: "a"}), query: "fp"},
+		{name: "other search", token: token, query: "other"},
+		{name: "not json", token: signed(codec, "not json"), query: "fp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.decode(tt.token, tt.query); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("decode = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []interface{}
+		same bool
+	}{
+		{name: "equal", a: []interface{}{"semantic", "vector", "q"}, b: []interface{}{"semantic", "vector", "q"}, same: true},
+		{name: "query", a: []interface{}{"semantic", "vector", "q"}, b: []interface{}{"semantic", "vector", "r"}},
+		{name: "mode", a: []interface{}{"semantic", "vector", "q"}, b: []interface{}{"semantic", "hybrid", "q"}},
+		{name: "boundaries", a: []interface{}{"ab", "c"}, b: []interface{}{"a", "bc"}},
+		{name: "nil min score", a: []interface{}{"q", nil}, b: []interface{}{"q", 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := fingerprint(tt.a...) == fingerprint(tt.b...); same != tt.same {
+				t.Fatalf("fingerprints equal = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+// signed returns payload signed by codec, as encode would.
+func signed(codec *cursorCodec, payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(codec.sign([]byte(payload)))
+}
+
+// flipFirst changes the first character of s. The last one of a base64
+// string may only carry padding bits.
+func flipFirst(s string) string {
+	replacement := "A"
+	if s[0] == 'A' {
+		replacement = "B"
+	}
+	return replacement + s[1:]
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"context"
+	"newstrix/internal/models"
+	"sort"
+	"sync"
+)
+
+// HybridCandidateFactor is how many more hits each ranking contributes than
+// the requested limit, so that items ranked well by only one of them can
+// still make the page.
+const HybridCandidateFactor = 2
+
+// HybridConfig tunes reciprocal rank fusion: an item scores
+// LexicalWeight/(K+lexical rank) + VectorWeight/(K+vector rank).
+type HybridConfig struct {
+	LexicalWeight float64
+	VectorWeight  float64
+	// K flattens the advantage of the top ranks.
+	K int
+}
+
+var DefaultHybridConfig = HybridConfig{
+	LexicalWeight: 1,
+	VectorWeight:  1,
+	K:             60,
+}
+
+func (s *SearchEngine) searchHybrid(ctx context.Context, request models.SearchParams) ([]models.NewsItem, error) {
+	limit := request.Limit
+	request.Limit = limit * HybridCandidateFactor
+
+	lexicalRequest := request
+	vectorRequest := request
+	vectorRequest.Mode = models.SearchModeVector
+
+	var (
+		wg                    sync.WaitGroup
+		lexical, vector       []models.NewsItem
+		lexicalErr, vectorErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		lexical, lexicalErr = s.storage.SearchFullText(ctx, lexicalRequest)
+	}()
+	go func() {
+		defer wg.Done()
+		vector, vectorErr = s.storage.SearchByFilters(ctx, vectorRequest)
+	}()
+	wg.Wait()
+
+	if lexicalErr != nil {
+		return nil, lexicalErr
+	}
+	if vectorErr != nil {
+		return nil, vectorErr
+	}
+
+	return fuse(lexical, vector, s.hybrid, limit), nil
+}
+
+// fuse merges two rankings with reciprocal rank fusion and returns the best
+// limit items.
+func fuse(lexical, vector []models.NewsItem, cfg HybridConfig, limit int) []models.NewsItem {
+	type fused struct {
+		item  models.NewsItem
+		score float64
+	}
+
+	byID := make(map[string]*fused, len(lexical)+len(vector))
+	add := func(items []models.NewsItem, weight float64) {
+		for rank, item := range items {
+			f, ok := byID[item.Guid]
+			if !ok {
+				f = &fused{item: item}
+				byID[item.Guid] = f
+			}
+			f.score += weight / float64(cfg.K+rank+1)
+		}
+	}
+	add(vector, cfg.VectorWeight)
+	add(lexical, cfg.LexicalWeight)
+
+	all := make([]*fused, 0, len(byID))
+	for _, f := range byID {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].item.Guid < all[j].item.Guid
+	})
+
+	items := make([]models.NewsItem, 0, min(limit, len(all)))
+	for _, f := range all[:min(limit, len(all))] {
+		f.item.Score = f.score
+		items = append(items, f.item)
+	}
+	return items
+}
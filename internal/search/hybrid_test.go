@@ -0,0 +1,108 @@
+package search
+
+import (
+	"newstrix/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestFuse(t *testing.T) {
+	tests := []struct {
+		name            string
+		lexical, vector []string
+		cfg             HybridConfig
+		limit           int
+		want            []string
+	}{
+		{
+			name:  "empty",
+			cfg:   DefaultHybridConfig,
+			limit: 10,
+			want:  []string{},
+		},
+		{
+			name:    "one ranking",
+			lexical: []string{"a", "b"},
+			cfg:     DefaultHybridConfig,
+			limit:   10,
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "rankings agree",
+			lexical: []string{"a", "b", "c"},
+			vector:  []string{"a", "b", "c"},
+			cfg:     DefaultHybridConfig,
+			limit:   10,
+			want:    []string{"a", "b", "c"},
+		},
+		{
+			name:    "found by both beats top of one",
+			lexical: []string{"c", "b"},
+			vector:  []string{"a", "b"},
+			cfg:     DefaultHybridConfig,
+			limit:   10,
+			want:    []string{"b", "a", "c"},
+		},
+		{
+			name:    "limit",
+			lexical: []string{"c", "b"},
+			vector:  []string{"a", "b"},
+			cfg:     DefaultHybridConfig,
+			limit:   2,
+			want:    []string{"b", "a"},
+		},
+		{
+			name:    "ties are stable",
+			lexical: []string{"b"},
+			vector:  []string{"a"},
+			cfg:     DefaultHybridConfig,
+			limit:   10,
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "lexical weight",
+			lexical: []string{"b"},
+			vector:  []string{"a"},
+			cfg:     HybridConfig{LexicalWeight: 2, VectorWeight: 1, K: 60},
+			limit:   10,
+			want:    []string{"b", "a"},
+		},
+		{
+			name:    "small k favors top ranks",
+			lexical: []string{"c"},
+			vector:  []string{"a", "b", "c"},
+			cfg:     HybridConfig{LexicalWeight: 1, VectorWeight: 1, K: 0},
+			limit:   10,
+			want:    []string{"c", "a", "b"},
+		},
+		{
+			name:    "large k favors agreement",
+			lexical: []string{"d", "c"},
+			vector:  []string{"a", "b", "c"},
+			cfg:     HybridConfig{LexicalWeight: 1, VectorWeight: 1, K: 60},
+			limit:   10,
+			want:    []string{"c", "a", "d", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fused := fuse(newsItems(tt.lexical), newsItems(tt.vector), tt.cfg, tt.limit)
+			got := make([]string, 0, len(fused))
+			for _, item := range fused {
+				got = append(got, item.Guid)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("fuse = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newsItems(ids []string) []models.NewsItem {
+	items := make([]models.NewsItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, models.NewsItem{Guid: id})
+	}
+	return items
+}
@@ -0,0 +1,233 @@
+package search
+
+import (
+	"context"
+	"newstrix/internal/models"
+	"strings"
+	"time"
+)
+
+// MaxHybridDepth is how far hybrid search can be paged. Fused rankings have
+// no sort key to continue from, so every page is fused from the start.
+const MaxHybridDepth = 1000
+
+// SearchModeFilters is reported as the mode of searches without a query
+// vector; their hits are ordered by full-text rank or by date.
+const SearchModeFilters = "filters"
+
+// What SearchResult.Score measures.
+const (
+	// ScoreSimilarity is the similarity to the query vector under
+	// EffectiveParams.Metric; higher is better.
+	ScoreSimilarity = "similarity"
+	// ScoreRank is the full-text rank; higher is better.
+	ScoreRank = "rank"
+	// ScoreFused is the reciprocal rank fusion score; higher is better.
+	ScoreFused = "rrf"
+)
+
+// SearchResult is one hit of a search.
+type SearchResult struct {
+	models.NewsItem
+	// Score is what the hit was ordered by, see PageInfo.ScoreType. It is
+	// not set when hits are ordered by date.
+	Score *float64 `json:"score,omitempty"`
+	// Rank is the 1-based position of the hit in the whole result list.
+	Rank int `json:"rank"`
+	// MatchedFields are the fields that contain the query terms.
+	MatchedFields []string `json:"matched_fields,omitempty"`
+}
+
+// EffectiveParams echo a search as it was run, after defaults were applied.
+type EffectiveParams struct {
+	Mode     string     `json:"mode"`
+	Query    string     `json:"query,omitempty"`
+	Keywords []string   `json:"keywords,omitempty"`
+	Source   string     `json:"source,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Limit    int        `json:"limit"`
+	Model    string     `json:"model,omitempty"`
+	Metric   string     `json:"metric,omitempty"`
+	MinScore *float64   `json:"min_score,omitempty"`
+	Collapse bool       `json:"collapse"`
+}
+
+// PageInfo describes a page of hits. Next is the cursor of the following
+// page, empty on the last one.
+type PageInfo struct {
+	Next      string
+	Params    EffectiveParams
+	ScoreType string
+}
+
+// Page is one page of search hits.
+type Page struct {
+	Items []SearchResult
+	PageInfo
+}
+
+// searchPage returns up to request.Limit hits after token.
+func (s *SearchEngine) searchPage(ctx context.Context, request models.SearchParams, fp, token string) (*Page, error) {
+	limit := request.Limit
+	hits, err := s.fetch(ctx, request, limit+1, fp, token)
+	if err != nil {
+		return nil, err
+	}
+
+	items := hits.items[:min(limit, len(hits.items))]
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Guid)
+	}
+	matched, err := s.matchFields(ctx, request, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Items:    make([]SearchResult, 0, len(items)),
+		PageInfo: pageInfo(request, false),
+	}
+	for i, item := range items {
+		page.Items = append(page.Items, newResult(item, hits.start.Rank+i+1, page.PageInfo, matched[item.Guid]))
+	}
+
+	if len(hits.items) > limit {
+		page.Next = s.cursors.encode(hits.after(limit-1, hits.start.Rank+limit))
+	}
+	return page, nil
+}
+
+// hits is a run of consecutive hits of a search, starting behind the cursor
+// start.
+type hits struct {
+	items []models.NewsItem
+	start cursor
+	fp    string
+}
+
+// after returns the cursor behind the i-th hit, with rank results shown up
+// to it.
+func (h *hits) after(i, rank int) cursor {
+	key := h.items[i].Key()
+	return cursor{
+		Query:       h.fp,
+		PublishedAt: key.PublishedAt,
+		Score:       key.Score,
+		ID:          key.ID,
+		Offset:      h.start.Offset + i + 1,
+		Rank:        rank,
+	}
+}
+
+// fetch returns up to n hits after token.
+func (s *SearchEngine) fetch(ctx context.Context, request models.SearchParams, n int, fp, token string) (*hits, error) {
+	h := &hits{fp: fp}
+	if token != "" {
+		var err error
+		if h.start, err = s.cursors.decode(token, fp); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Mode == models.SearchModeHybrid {
+		request.Limit = min(h.start.Offset+n, MaxHybridDepth)
+		items, err := s.searchHybrid(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		h.items = items[min(h.start.Offset, len(items)):]
+		return h, nil
+	}
+
+	if token != "" {
+		request.After = h.start.key()
+	}
+	request.Limit = n
+	items, err := s.storage.SearchByFilters(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	h.items = items
+	return h, nil
+}
+
+// newResult makes the result at rank from item on a page described by info.
+// matched are the text fields of item that contain the query terms.
+func newResult(item models.NewsItem, rank int, info PageInfo, matched []string) SearchResult {
+	result := SearchResult{NewsItem: item, Rank: rank, MatchedFields: matched}
+	switch info.ScoreType {
+	case "":
+	case ScoreSimilarity:
+		// Vector hits are ordered by distance, see models.Similarity.
+		score := models.Similarity(info.Params.Metric, item.Score)
+		result.Score = &score
+	default:
+		score := item.Score
+		result.Score = &score
+	}
+	if item.Passage != "" {
+		result.MatchedFields = append(result.MatchedFields, "passage")
+	}
+	return result
+}
+
+// matchFields returns the text fields of the items with the given ids that
+// contain the query text or keywords.
+func (s *SearchEngine) matchFields(ctx context.Context, request models.SearchParams, ids []string) (map[string][]string, error) {
+	text := lexicalText(request)
+	if text == "" || len(ids) == 0 {
+		return nil, nil
+	}
+	return s.storage.MatchedFields(ctx, ids, text)
+}
+
+// lexicalText is the text full-text matching runs on: the query and the
+// keywords.
+func lexicalText(request models.SearchParams) string {
+	var parts []string
+	if request.Text != "" {
+		parts = append(parts, request.Text)
+	}
+	if request.Keywords != nil {
+		parts = append(parts, *request.Keywords...)
+	}
+	return strings.Join(parts, " ")
+}
+
+func pageInfo(request models.SearchParams, collapse bool) PageInfo {
+	params := EffectiveParams{
+		Mode:     request.Mode,
+		Query:    request.Text,
+		From:     request.From,
+		To:       request.To,
+		Limit:    request.Limit,
+		Model:    request.Model,
+		Collapse: collapse,
+	}
+	if request.Keywords != nil {
+		params.Keywords = *request.Keywords
+	}
+	if request.Source != nil {
+		params.Source = *request.Source
+	}
+	if request.Vector != nil && len(*request.Vector) > 0 {
+		params.Metric = request.Metric
+		params.MinScore = request.MinScore
+	}
+
+	info := PageInfo{Params: params}
+	switch {
+	case request.Mode == models.SearchModeHybrid:
+		info.ScoreType = ScoreFused
+	case request.Vector != nil && len(*request.Vector) > 0:
+		info.ScoreType = ScoreSimilarity
+	case request.Keywords != nil && len(*request.Keywords) > 0:
+		info.Params.Mode = SearchModeFilters
+		info.ScoreType = ScoreRank
+	default:
+		info.Params.Mode = SearchModeFilters
+	}
+	return info
+}
@@ -15,32 +15,104 @@ const (
 	DefaultDateRange = 6 * time.Hour
 )
 
+// ParseMode validates the search mode parameter; an empty value selects
+// models.SearchModeVector.
+func ParseMode(value string) (string, error) {
+	switch value {
+	case "":
+		return models.SearchModeVector, nil
+	case models.SearchModeVector, models.SearchModeChunks, models.SearchModeHybrid:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q", value)
+	}
+}
+
+// ParseMetric validates the vector metric setting; an empty value selects
+// models.MetricCosine.
+func ParseMetric(value string) (string, error) {
+	switch value {
+	case "":
+		return models.MetricCosine, nil
+	case models.MetricCosine, models.MetricInnerProduct, models.MetricL2:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown vector metric %q", value)
+	}
+}
+
 type QueryOption struct {
 	Query *string
+	// Cursor continues a previous search, see Page.Next.
+	Cursor string
 	models.SearchParams
 }
 
 type SearchRepository interface {
 	GetByID(ctx context.Context, id string) (*models.NewsItem, error)
+	GetRevisions(ctx context.Context, id string) ([]models.NewsRevision, error)
 	SearchByFilters(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error)
+	SearchFullText(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error)
+	MatchedFields(ctx context.Context, ids []string, text string) (map[string][]string, error)
+	ClusterPublishers(ctx context.Context, clusterIDs []string) (map[string][]string, error)
 }
 
 type Vectorizer interface {
 	Vectorize(ctx context.Context, text string) ([]float32, error)
+	// Model names the model behind Vectorize, so that only vectors of the
+	// same model are compared.
+	Model(ctx context.Context) (string, error)
 }
 
 type SearchEngine struct {
 	ctx      context.Context
 	embedder Vectorizer
 	storage  SearchRepository
+	hybrid   HybridConfig
+	metric   string
+	cursors  *cursorCodec
+}
+
+type Option func(*SearchEngine)
+
+// WithCursorSecret signs pagination cursors with secret. Without it a random
+// secret is used, and cursors do not survive a restart.
+func WithCursorSecret(secret []byte) Option {
+	return func(s *SearchEngine) {
+		s.cursors = newCursorCodec(secret)
+	}
+}
+
+// WithHybrid sets how full-text and vector hits are fused in hybrid mode.
+func WithHybrid(cfg HybridConfig) Option {
+	return func(s *SearchEngine) {
+		s.hybrid = cfg
+	}
+}
+
+// WithMetric sets how query vectors are compared with stored ones, see
+// models.MetricCosine.
+func WithMetric(metric string) Option {
+	return func(s *SearchEngine) {
+		s.metric = metric
+	}
 }
 
-func NewSearchEngine(ctx context.Context, embedder Vectorizer, storage SearchRepository) *SearchEngine {
-	return &SearchEngine{
+func NewSearchEngine(ctx context.Context, embedder Vectorizer, storage SearchRepository, opts ...Option) *SearchEngine {
+	s := &SearchEngine{
 		ctx:      ctx,
 		embedder: embedder,
 		storage:  storage,
+		hybrid:   DefaultHybridConfig,
+		metric:   models.MetricCosine,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cursors == nil {
+		s.cursors = newCursorCodec(nil)
 	}
+	return s
 }
 
 func (s *SearchEngine) GetByID(ctx context.Context, id *string) (*models.NewsItem, error) {
@@ -54,6 +126,14 @@ func (s *SearchEngine) GetByID(ctx context.Context, id *string) (*models.NewsIte
 	return item, nil
 }
 
+// GetRevisions returns the stored versions of an item, oldest first.
+func (s *SearchEngine) GetRevisions(ctx context.Context, id string) ([]models.NewsRevision, error) {
+	if id == "" {
+		return nil, nil
+	}
+	return s.storage.GetRevisions(ctx, id)
+}
+
 func (s *SearchEngine) SearchByKeywords(ctx context.Context, keywords []string) ([]models.NewsItem, error) {
 	if len(keywords) == 0 {
 		return nil, fmt.Errorf("keywords cannot be empty")
@@ -69,9 +149,34 @@ func (s *SearchEngine) SearchByKeywords(ctx context.Context, keywords []string)
 	return items, nil
 }
 
-func (s *SearchEngine) SearchBySemanticQuery(ctx context.Context, query string, limit int) ([]models.NewsItem, error) {
+// SearchBySemanticQuery returns the page of hits for query after cursor; an
+// empty cursor starts at the first hit. Hits scoring below a non-nil minScore
+// are dropped.
+func (s *SearchEngine) SearchBySemanticQuery(ctx context.Context, query string, limit int, mode string, minScore *float64, cursor string) (*Page, error) {
+	request, fp, err := s.semanticRequest(ctx, query, limit, mode, minScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.searchPage(ctx, request, fp, cursor)
+}
+
+// SearchBySemanticQueryCollapsed is SearchBySemanticQuery with hits of the
+// same story cluster folded into one.
+func (s *SearchEngine) SearchBySemanticQueryCollapsed(ctx context.Context, query string, limit int, mode string, minScore *float64, cursor string) (*CollapsedPage, error) {
+	request, fp, err := s.semanticRequest(ctx, query, limit, mode, minScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.searchCollapsed(ctx, request, fp, cursor)
+}
+
+// semanticRequest builds the storage request for a semantic search and the
+// fingerprint its cursors are bound to.
+func (s *SearchEngine) semanticRequest(ctx context.Context, query string, limit int, mode string, minScore *float64) (models.SearchParams, string, error) {
 	if query == "" {
-		return nil, fmt.Errorf("invalid query: query='%s'", query)
+		return models.SearchParams{}, "", fmt.Errorf("invalid query: query='%s'", query)
 	}
 	if len(query) > MaxQueryLength {
 		query = query[:MaxQueryLength]
@@ -82,37 +187,74 @@ func (s *SearchEngine) SearchBySemanticQuery(ctx context.Context, query string,
 	if limit > MaxLimit {
 		limit = MaxLimit
 	}
+	model, err := s.embedder.Model(ctx)
+	if err != nil {
+		return models.SearchParams{}, "", err
+	}
 	vec, err := s.embedder.Vectorize(ctx, query)
+	if err != nil {
+		return models.SearchParams{}, "", err
+	}
+
+	return models.SearchParams{
+		Mode:     mode,
+		Text:     query,
+		Vector:   &vec,
+		Model:    model,
+		Limit:    limit,
+		Metric:   s.metric,
+		MinScore: minScore,
+	}, fingerprint("semantic", mode, query, model, s.metric, minScore), nil
+}
+
+// SearchAdvanced returns the page of hits after params.Cursor; an empty
+// cursor starts at the first hit.
+func (s *SearchEngine) SearchAdvanced(ctx context.Context, params QueryOption) (*Page, error) {
+	request, fp, err := s.advancedRequest(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	items, err := s.storage.SearchByFilters(ctx, models.SearchParams{
-		Vector: &vec,
-		Limit:  limit,
-	})
+	return s.searchPage(ctx, request, fp, params.Cursor)
+}
+
+// SearchAdvancedCollapsed is SearchAdvanced with hits of the same story
+// cluster folded into one.
+func (s *SearchEngine) SearchAdvancedCollapsed(ctx context.Context, params QueryOption) (*CollapsedPage, error) {
+	request, fp, err := s.advancedRequest(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	return items, nil
+
+	return s.searchCollapsed(ctx, request, fp, params.Cursor)
 }
 
-func (s *SearchEngine) SearchAdvanced(ctx context.Context, params QueryOption) ([]models.NewsItem, error) {
+// advancedRequest builds the storage request for a filter search and the
+// fingerprint its cursors are bound to.
+func (s *SearchEngine) advancedRequest(ctx context.Context, params QueryOption) (models.SearchParams, string, error) {
 
 	if params.Query == nil && params.Source == nil && params.From == nil && params.To == nil && params.Keywords == nil {
-		return nil, fmt.Errorf("at least one search parameter must be provided")
+		return models.SearchParams{}, "", fmt.Errorf("at least one search parameter must be provided")
+	}
+
+	if params.Mode == models.SearchModeHybrid && params.Query == nil {
+		return models.SearchParams{}, "", fmt.Errorf("hybrid search requires a query")
 	}
 
 	if params.Source != nil {
 		if len(*params.Source) > MaxSourceLength {
-			return nil, fmt.Errorf("source name too long")
+			return models.SearchParams{}, "", fmt.Errorf("source name too long")
 		}
 	}
 
 	if params.From != nil && params.To != nil && params.From.After(*params.To) {
-		return nil, fmt.Errorf("invalid date range: from='%s', to='%s'", params.From, params.To)
+		return models.SearchParams{}, "", fmt.Errorf("invalid date range: from='%s', to='%s'", params.From, params.To)
 	}
 
+	// Cursors are bound to the dates as requested: an open range ends at
+	// the time of each request.
+	from, to := params.From, params.To
+
 	if params.Limit <= 0 {
 		params.Limit = DefaultLimit
 	}
@@ -130,21 +272,31 @@ func (s *SearchEngine) SearchAdvanced(ctx context.Context, params QueryOption) (
 	}
 
 	if params.Query != nil {
+		params.Text = *params.Query
+
+		model, err := s.embedder.Model(ctx)
+		if err != nil {
+			return models.SearchParams{}, "", fmt.Errorf("error getting embedding model: %w", err)
+		}
 		vec, err := s.embedder.Vectorize(ctx, *params.Query)
 		if err != nil {
-			return nil, fmt.Errorf("error vectorizing query: %w", err)
+			return models.SearchParams{}, "", fmt.Errorf("error vectorizing query: %w", err)
 		}
 		params.Vector = &vec
+		params.Model = model
 	}
 
-	request := models.SearchParams{
+	return models.SearchParams{
+		Mode:     params.Mode,
+		Text:     params.Text,
 		Keywords: params.Keywords,
 		Vector:   params.Vector,
+		Model:    params.Model,
 		Source:   params.Source,
 		From:     params.From,
 		To:       params.To,
 		Limit:    params.Limit,
-	}
-
-	return s.storage.SearchByFilters(ctx, request)
+		Metric:   s.metric,
+		MinScore: params.MinScore,
+	}, fingerprint("filters", params.Mode, params.Query, params.Keywords, params.Source, from, to, params.Model, s.metric, params.MinScore), nil
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"newstrix/internal/cluster"
 	"newstrix/internal/models"
 	"newstrix/internal/storage/postgres"
 	"time"
@@ -10,8 +11,33 @@ import (
 type Facade interface {
 	AddNews(ctx context.Context, news *[]models.NewsItem, source string, updateAt time.Time) error
 	GetByID(ctx context.Context, id string) (*models.NewsItem, error)
+	GetRevisions(ctx context.Context, id string) ([]models.NewsRevision, error)
 	SearchByFilters(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error)
+	SearchFullText(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error)
+	MatchedFields(ctx context.Context, ids []string, text string) (map[string][]string, error)
 	GetSourceLastParsed(ctx context.Context, source string) (time.Time, error)
+	StoredHashes(ctx context.Context, ids []string) (map[string]string, error)
+	LinkOwners(ctx context.Context, links []string) (map[string]string, error)
+	GetSourceValidators(ctx context.Context, source string) (models.CacheValidators, error)
+	UpdateSourceValidators(ctx context.Context, source string, validators models.CacheValidators) error
+	GetSourceHealth(ctx context.Context, source string) (*models.SourceHealth, error)
+	SaveSourceHealth(ctx context.Context, health *models.SourceHealth) error
+	ListSourceHealth(ctx context.Context) ([]models.SourceHealth, error)
+	NearestNews(ctx context.Context, model string, vector []float32, from, to time.Time, limit int) ([]cluster.Neighbour, error)
+	ClusterPublishers(ctx context.Context, clusterIDs []string) (map[string][]string, error)
+	GetEmbeddings(ctx context.Context, model string, hashes []string, since time.Time) (map[string][]float32, error)
+	PutEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error
+	DeleteEmbeddings(ctx context.Context, before time.Time) (int64, error)
+	CountToReembed(ctx context.Context, model string) (int, error)
+	NewsToReembed(ctx context.Context, model string, after string, limit int) ([]models.NewsItem, error)
+	SaveReembedBatch(ctx context.Context, items []models.NewsItem, progress models.ReembedProgress) (models.ReembedProgress, error)
+	GetReembedProgress(ctx context.Context, model string) (*models.ReembedProgress, error)
+	DeleteReembedProgress(ctx context.Context, model string) error
+	ClaimPendingNews(ctx context.Context, limit int, lease time.Duration) ([]models.NewsItem, error)
+	CompletePendingNews(ctx context.Context, items []models.NewsItem) (int, error)
+	RefreshClusters(ctx context.Context, clusterIDs []string) error
+	DeferPendingNews(ctx context.Context, items []models.NewsItem, baseDelay, maxDelay time.Duration) error
+	CountPendingNews(ctx context.Context) (int, error)
 }
 
 type StorageFacade struct {
@@ -28,8 +54,18 @@ func NewStorageFacade(txManager postgres.TransactionManager, pgRepository *postg
 
 func (f *StorageFacade) AddNews(ctx context.Context, news *[]models.NewsItem, source string, updateAt time.Time) error {
 	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
-		if err := f.pgRepository.AddNews(ctxTx, *news); err != nil {
-			return err
+		if len(*news) > 0 {
+			if err := f.pgRepository.ArchiveRevisions(ctxTx, *news); err != nil {
+				return err
+			}
+
+			if err := f.pgRepository.AddNews(ctxTx, *news); err != nil {
+				return err
+			}
+
+			if err := f.pgRepository.ReplaceChunks(ctxTx, *news); err != nil {
+				return err
+			}
 		}
 
 		if err := f.pgRepository.UpdateSourceLastParsed(ctxTx, source, updateAt); err != nil {
@@ -45,9 +81,190 @@ func (f *StorageFacade) GetByID(ctx context.Context, id string) (*models.NewsIte
 }
 
 func (f *StorageFacade) SearchByFilters(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error) {
-	return f.pgRepository.SearchByFilters(ctx, opt)
+	if opt.Vector == nil || len(*opt.Vector) == 0 {
+		return f.pgRepository.SearchByFilters(ctx, opt)
+	}
+
+	// Vector searches tune the index scan with settings local to a transaction.
+	var items []models.NewsItem
+	err := f.txManager.RunReadUncommitted(ctx, func(ctxTx context.Context) error {
+		var err error
+		items, err = f.pgRepository.SearchByFilters(ctxTx, opt)
+		return err
+	})
+	return items, err
+}
+
+func (f *StorageFacade) SearchFullText(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error) {
+	return f.pgRepository.SearchFullText(ctx, opt)
+}
+
+func (f *StorageFacade) MatchedFields(ctx context.Context, ids []string, text string) (map[string][]string, error) {
+	return f.pgRepository.MatchedFields(ctx, ids, text)
 }
 
 func (f *StorageFacade) GetSourceLastParsed(ctx context.Context, source string) (time.Time, error) {
 	return f.pgRepository.GetSourceLastParsed(ctx, source)
 }
+
+func (f *StorageFacade) StoredHashes(ctx context.Context, ids []string) (map[string]string, error) {
+	return f.pgRepository.StoredHashes(ctx, ids)
+}
+
+func (f *StorageFacade) GetRevisions(ctx context.Context, id string) ([]models.NewsRevision, error) {
+	return f.pgRepository.GetRevisions(ctx, id)
+}
+
+func (f *StorageFacade) GetSourceValidators(ctx context.Context, source string) (models.CacheValidators, error) {
+	return f.pgRepository.GetSourceValidators(ctx, source)
+}
+
+func (f *StorageFacade) UpdateSourceValidators(ctx context.Context, source string, validators models.CacheValidators) error {
+	return f.pgRepository.UpdateSourceValidators(ctx, source, validators)
+}
+
+func (f *StorageFacade) GetSourceHealth(ctx context.Context, source string) (*models.SourceHealth, error) {
+	return f.pgRepository.GetSourceHealth(ctx, source)
+}
+
+func (f *StorageFacade) SaveSourceHealth(ctx context.Context, health *models.SourceHealth) error {
+	return f.pgRepository.SaveSourceHealth(ctx, health)
+}
+
+func (f *StorageFacade) ListSourceHealth(ctx context.Context) ([]models.SourceHealth, error) {
+	return f.pgRepository.ListSourceHealth(ctx)
+}
+
+func (f *StorageFacade) LinkOwners(ctx context.Context, links []string) (map[string]string, error) {
+	return f.pgRepository.LinkOwners(ctx, links)
+}
+
+func (f *StorageFacade) NearestNews(ctx context.Context, model string, vector []float32, from, to time.Time, limit int) ([]cluster.Neighbour, error) {
+	var neighbours []cluster.Neighbour
+	err := f.txManager.RunReadUncommitted(ctx, func(ctxTx context.Context) error {
+		var err error
+		neighbours, err = f.pgRepository.NearestNews(ctxTx, model, vector, from, to, limit)
+		return err
+	})
+	return neighbours, err
+}
+
+func (f *StorageFacade) ClusterPublishers(ctx context.Context, clusterIDs []string) (map[string][]string, error) {
+	return f.pgRepository.ClusterPublishers(ctx, clusterIDs)
+}
+
+func (f *StorageFacade) GetEmbeddings(ctx context.Context, model string, hashes []string, since time.Time) (map[string][]float32, error) {
+	return f.pgRepository.GetEmbeddings(ctx, model, hashes, since)
+}
+
+func (f *StorageFacade) PutEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error {
+	return f.pgRepository.PutEmbeddings(ctx, model, vectors)
+}
+
+func (f *StorageFacade) DeleteEmbeddings(ctx context.Context, before time.Time) (int64, error) {
+	return f.pgRepository.DeleteEmbeddings(ctx, before)
+}
+
+func (f *StorageFacade) CountToReembed(ctx context.Context, model string) (int, error) {
+	return f.pgRepository.CountToReembed(ctx, model)
+}
+
+func (f *StorageFacade) NewsToReembed(ctx context.Context, model string, after string, limit int) ([]models.NewsItem, error) {
+	return f.pgRepository.NewsToReembed(ctx, model, after, limit)
+}
+
+// SaveReembedBatch stores new vectors together with the progress that covers
+// them, so that a resumed run neither skips nor repeats items. The stored
+// items are added to progress.Processed; items revised since they were read
+// keep their vector and are added to progress.Failed, so that the next run
+// retries them. It returns the saved progress.
+func (f *StorageFacade) SaveReembedBatch(ctx context.Context, items []models.NewsItem, progress models.ReembedProgress) (models.ReembedProgress, error) {
+	saved := progress
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		updated, err := f.pgRepository.UpdateEmbeddings(ctxTx, items)
+		if err != nil {
+			return err
+		}
+
+		if err := f.pgRepository.ReplaceChunks(ctxTx, updated); err != nil {
+			return err
+		}
+
+		saved = progress
+		saved.Processed += len(updated)
+		saved.Failed += len(items) - len(updated)
+		return f.pgRepository.SaveReembedProgress(ctxTx, saved)
+	})
+	if err != nil {
+		return progress, err
+	}
+	return saved, nil
+}
+
+func (f *StorageFacade) GetReembedProgress(ctx context.Context, model string) (*models.ReembedProgress, error) {
+	return f.pgRepository.GetReembedProgress(ctx, model)
+}
+
+func (f *StorageFacade) DeleteReembedProgress(ctx context.Context, model string) error {
+	return f.pgRepository.DeleteReembedProgress(ctx, model)
+}
+
+func (f *StorageFacade) ClaimPendingNews(ctx context.Context, limit int, lease time.Duration) ([]models.NewsItem, error) {
+	return f.pgRepository.ClaimPendingNews(ctx, limit, lease)
+}
+
+// CompletePendingNews stores the vectors and chunks of items that were
+// pending, then refreshes their clusters. Items revised since they were
+// claimed stay pending. It returns how many items were stored.
+func (f *StorageFacade) CompletePendingNews(ctx context.Context, items []models.NewsItem) (int, error) {
+	var updated []models.NewsItem
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		var err error
+		if updated, err = f.pgRepository.UpdateEmbeddings(ctxTx, items); err != nil {
+			return err
+		}
+
+		return f.pgRepository.ReplaceChunks(ctxTx, updated)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(updated), f.RefreshClusters(ctx, ClusterIDs(updated))
+}
+
+// RefreshClusters recomputes the given story clusters. It runs in a
+// transaction of its own, after the news of the clusters are committed:
+// concurrent refreshes of a shared cluster would fail each other's
+// serializable transactions.
+func (f *StorageFacade) RefreshClusters(ctx context.Context, clusterIDs []string) error {
+	if len(clusterIDs) == 0 {
+		return nil
+	}
+	return f.pgRepository.RefreshClusters(ctx, clusterIDs)
+}
+
+func (f *StorageFacade) DeferPendingNews(ctx context.Context, items []models.NewsItem, baseDelay, maxDelay time.Duration) error {
+	return f.pgRepository.DeferPendingNews(ctx, items, baseDelay, maxDelay)
+}
+
+func (f *StorageFacade) CountPendingNews(ctx context.Context) (int, error) {
+	return f.pgRepository.CountPendingNews(ctx)
+}
+
+// ClusterIDs returns the distinct clusters of news.
+func ClusterIDs(news []models.NewsItem) []string {
+	seen := make(map[string]struct{}, len(news))
+	var ids []string
+	for _, item := range news {
+		if item.ClusterID == "" {
+			continue
+		}
+		if _, ok := seen[item.ClusterID]; ok {
+			continue
+		}
+		seen[item.ClusterID] = struct{}{}
+		ids = append(ids, item.ClusterID)
+	}
+	return ids
+}
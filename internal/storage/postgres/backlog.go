@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"newstrix/internal/models"
+	"strings"
+	"time"
+)
+
+// maxBackoffDoublings caps the exponent of the retry delay so that the
+// interval arithmetic cannot overflow; the delay is capped anyway.
+const maxBackoffDoublings = 20
+
+// ClaimPendingNews returns up to limit pending items that are due and leases
+// them for lease, so that concurrent workers skip them until the lease runs
+// out.
+func (r *PgRepository) ClaimPendingNews(ctx context.Context, limit int, lease time.Duration) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	columns := make([]string, len(newsColumns))
+	for i, c := range newsColumns {
+		columns[i] = "n." + c
+	}
+
+	query := `UPDATE news n SET next_embedding_at = NOW() + make_interval(secs => $2)
+		FROM (
+			SELECT id FROM news
+			WHERE embedding_status = 'pending' AND (next_embedding_at IS NULL OR next_embedding_at <= NOW())
+			ORDER BY next_embedding_at NULLS FIRST, published_at DESC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE n.id = due.id
+		RETURNING ` + strings.Join(columns, ", ")
+	rows, err := tx.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending news: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.NewsItem
+	for rows.Next() {
+		item, err := scanNews(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+// DeferPendingNews records a failed attempt for each item and schedules the
+// next one after baseDelay doubled per attempt, at most maxDelay.
+func (r *PgRepository) DeferPendingNews(ctx context.Context, items []models.NewsItem, baseDelay, maxDelay time.Duration) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	ids := make([]string, len(items))
+	errs := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Guid
+		errs[i] = item.EmbeddingError
+	}
+
+	query := `UPDATE news n SET
+		embedding_attempts = n.embedding_attempts + 1,
+		embedding_error = NULLIF(failed.error, ''),
+		next_embedding_at = NOW() + LEAST(
+			make_interval(secs => $3 * power(2, LEAST(n.embedding_attempts, $5))),
+			make_interval(secs => $4))
+		FROM unnest($1::text[], $2::text[]) AS failed (id, error)
+		WHERE n.id = failed.id AND n.embedding_status = 'pending'`
+	if _, err := tx.Exec(ctx, query, ids, errs, baseDelay.Seconds(), maxDelay.Seconds(), maxBackoffDoublings); err != nil {
+		return fmt.Errorf("failed to defer pending news: %w", err)
+	}
+
+	return nil
+}
+
+// CountPendingNews returns how many items wait for a vector.
+func (r *PgRepository) CountPendingNews(ctx context.Context) (int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM news WHERE embedding_status = 'pending'").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending news: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pgvector/pgvector-go"
+	"math"
+	"newstrix/internal/models"
+	"strings"
+)
+
+// chunkInsertBatch keeps chunk inserts well below the bind parameter limit.
+const chunkInsertBatch = 1000
+
+// ReplaceChunks replaces the stored chunks of every given item with its
+// Chunks. Items without chunks lose the stored ones.
+func (r *PgRepository) ReplaceChunks(ctx context.Context, news []models.NewsItem) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	ids := make([]string, 0, len(news))
+	var chunks []models.NewsChunk
+	for _, item := range news {
+		ids = append(ids, item.Guid)
+		chunks = append(chunks, item.Chunks...)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM news_chunks WHERE news_id = ANY($1)", ids); err != nil {
+		return fmt.Errorf("failed to delete news chunks: %w", err)
+	}
+
+	for start := 0; start < len(chunks); start += chunkInsertBatch {
+		batch := chunks[start:min(start+chunkInsertBatch, len(chunks))]
+
+		values := make([]interface{}, 0, 6*len(batch))
+		placeholders := make([]string, 0, len(batch))
+		for _, c := range batch {
+			n := len(values)
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+			values = append(values, c.NewsID, c.Index, c.Text, pgvector.NewVector(c.Vector), c.EmbeddingModel, c.EmbeddingDim)
+		}
+
+		query := "INSERT INTO news_chunks (news_id, chunk_index, text, vector, embedding_model, embedding_dim) VALUES " + strings.Join(placeholders, ", ")
+		if _, err := tx.Exec(ctx, query, values...); err != nil {
+			return fmt.Errorf("failed to insert news chunks: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// chunkCandidateFactor is how many nearest chunks per requested item passage
+// search takes from the index, since several chunks of one item may rank high.
+const chunkCandidateFactor = 8
+
+// searchChunks ranks items by the distance of their closest chunk to the
+// query vector and returns that chunk as the passage. The per-model HNSW index
+// on chunk vectors supplies the nearest chunks as candidates; rankByVector
+// falls back to taking the closest chunk of every item when the candidates do
+// not fill the page.
+func (r *PgRepository) searchChunks(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	distance := vectorDistance(opt.Metric, "vector", *opt.Vector)
+	candidates := opt.Limit * chunkCandidateFactor
+
+	var items []models.NewsItem
+	err := r.rankByVector(ctx, opt.Limit, candidates, opt.After == nil && !hasNewsFilters(opt), func(exact bool) (int, error) {
+		chunks := sq.Select("news_id", "text").
+			Column(sq.Alias(distance, "distance")).
+			From("news_chunks").
+			Where(sq.Eq{"embedding_model": opt.Model})
+		if !exact {
+			chunks = chunks.OrderBy("distance").Limit(uint64(candidates))
+		}
+		best := sq.Select("DISTINCT ON (news_id) news_id, text, distance").
+			FromSelect(chunks, "chunks").
+			OrderBy("news_id", "distance")
+
+		columns := make([]string, 0, len(newsColumns)+2)
+		for _, c := range newsColumns {
+			columns = append(columns, "n."+c)
+		}
+		columns = append(columns, "best.text")
+
+		columns = append(columns, "best.distance")
+
+		qb := sq.Select(columns...).
+			FromSelect(best, "best").
+			Join("news n ON n.id = best.news_id").
+			OrderBy("best.distance", "n.id").
+			Limit(uint64(opt.Limit)).
+			PlaceholderFormat(sq.Dollar)
+		qb = applyNewsFilters(qb, opt, "n.")
+		if cutoff := maxDistance(opt); !math.IsInf(cutoff, 1) {
+			qb = qb.Where(sq.LtOrEq{"best.distance": cutoff})
+		}
+		if opt.After != nil {
+			qb = qb.Where("(best.distance, n.id) > (?, ?)", opt.After.Score, opt.After.ID)
+		}
+
+		query, args, err := qb.ToSql()
+		if err != nil {
+			return 0, err
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		items = items[:0]
+		for rows.Next() {
+			var passage string
+			var distance float64
+			item, err := scanNews(rows, &passage, &distance)
+			if err != nil {
+				return 0, err
+			}
+			item.Passage = passage
+			item.Score = distance
+			items = append(items, *item)
+		}
+		return len(items), rows.Err()
+	})
+	return items, err
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/pgvector/pgvector-go"
+	"newstrix/internal/cluster"
+	"time"
+)
+
+func (r *PgRepository) NearestNews(ctx context.Context, model string, vector []float32, from, to time.Time, limit int) ([]cluster.Neighbour, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	distance := fmt.Sprintf("vector::vector(%d) <=> $1", len(vector))
+	query := `SELECT id, COALESCE(cluster_id, id), published_at, 1 - (` + distance + `) AS similarity
+		FROM news
+		WHERE published_at BETWEEN $2 AND $3 AND vector IS NOT NULL AND embedding_model = $5
+		ORDER BY ` + distance + `
+		LIMIT $4`
+
+	// The time window is a filter the HNSW index would apply only after
+	// picking its candidates, so the window is ranked exactly.
+	var neighbours []cluster.Neighbour
+	err := r.rankByVector(ctx, limit, limit, false, func(bool) (int, error) {
+		rows, err := tx.Query(ctx, query, pgvector.NewVector(vector), from, to, limit, model)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query nearest news: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var n cluster.Neighbour
+			if err := rows.Scan(&n.ID, &n.ClusterID, &n.PublishedAt, &n.Similarity); err != nil {
+				return 0, err
+			}
+			neighbours = append(neighbours, n)
+		}
+		return len(neighbours), rows.Err()
+	})
+	return neighbours, err
+}
+
+// RefreshClusters recomputes the canonical (earliest) item, time span and size
+// of the given clusters from the news table.
+func (r *PgRepository) RefreshClusters(ctx context.Context, clusterIDs []string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `INSERT INTO story_clusters (id, canonical_id, first_published_at, last_published_at, size)
+		SELECT cluster_id, (array_agg(id ORDER BY published_at, id))[1], MIN(published_at), MAX(published_at), COUNT(*)
+		FROM news
+		WHERE cluster_id = ANY($1)
+		GROUP BY cluster_id
+		ON CONFLICT (id) DO UPDATE SET
+			canonical_id = EXCLUDED.canonical_id,
+			first_published_at = EXCLUDED.first_published_at,
+			last_published_at = EXCLUDED.last_published_at,
+			size = EXCLUDED.size`
+	if _, err := tx.Exec(ctx, query, clusterIDs); err != nil {
+		return fmt.Errorf("failed to refresh story clusters: %w", err)
+	}
+
+	return nil
+}
+
+// ClusterPublishers returns the distinct publishers of every given cluster.
+func (r *PgRepository) ClusterPublishers(ctx context.Context, clusterIDs []string) (map[string][]string, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `SELECT cluster_id, array_agg(DISTINCT publisher ORDER BY publisher)
+		FROM news
+		WHERE cluster_id = ANY($1)
+		GROUP BY cluster_id`
+	rows, err := tx.Query(ctx, query, clusterIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cluster publishers: %w", err)
+	}
+	defer rows.Close()
+
+	publishers := make(map[string][]string, len(clusterIDs))
+	for rows.Next() {
+		var id string
+		var list []string
+		if err := rows.Scan(&id, &list); err != nil {
+			return nil, err
+		}
+		publishers[id] = list
+	}
+
+	return publishers, rows.Err()
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/pgvector/pgvector-go"
+	"strings"
+	"time"
+)
+
+func (r *PgRepository) GetEmbeddings(ctx context.Context, model string, hashes []string, since time.Time) (map[string][]float32, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT text_hash, vector FROM embedding_cache WHERE model = $1 AND text_hash = ANY($2) AND created_at >= $3", model, hashes, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make(map[string][]float32, len(hashes))
+	for rows.Next() {
+		var hash string
+		var v pgvector.Vector
+		if err := rows.Scan(&hash, &v); err != nil {
+			return nil, err
+		}
+		vectors[hash] = v.Slice()
+	}
+
+	return vectors, rows.Err()
+}
+
+// PutEmbeddings stores vectors by text hash, refreshing the write time of
+// existing entries.
+func (r *PgRepository) PutEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	values := make([]interface{}, 0, 3*len(vectors))
+	placeholders := make([]string, 0, len(vectors))
+	for hash, vector := range vectors {
+		n := len(values)
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		values = append(values, model, hash, pgvector.NewVector(vector))
+	}
+
+	query := "INSERT INTO embedding_cache (model, text_hash, vector) VALUES " + strings.Join(placeholders, ", ") +
+		" ON CONFLICT (model, text_hash) DO UPDATE SET vector = EXCLUDED.vector, created_at = NOW()"
+	if _, err := tx.Exec(ctx, query, values...); err != nil {
+		return fmt.Errorf("failed to write embedding cache: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEmbeddings removes cache entries written before the given time.
+func (r *PgRepository) DeleteEmbeddings(ctx context.Context, before time.Time) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	tag, err := tx.Exec(ctx, "DELETE FROM embedding_cache WHERE created_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up embedding cache: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
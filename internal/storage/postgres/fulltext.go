@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"newstrix/internal/models"
+	"strings"
+)
+
+// SearchFullText ranks items matching opt.Text by ts_rank over the title,
+// description and full text. The other filters of opt apply as in
+// SearchByFilters.
+func (r *PgRepository) SearchFullText(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	qb := sq.Select(newsColumns...).
+		Column(sq.Alias(textRank(opt.Text, ""), "score")).
+		From("news").
+		Where(textMatch(opt.Text, "")).
+		OrderBy("score DESC", "id DESC").
+		Limit(uint64(opt.Limit)).
+		PlaceholderFormat(sq.Dollar)
+	qb = applyNewsFilters(qb, opt, "")
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.NewsItem
+	for rows.Next() {
+		var score float64
+		item, err := scanNews(rows, &score)
+		if err != nil {
+			return nil, err
+		}
+		item.Score = score
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+// textMatch matches items against a query typed by a user. news_tsquery
+// parses it with websearch syntax in the russian and english configurations,
+// so inflected forms match and the GIN index on search_vector is used.
+func textMatch(text, prefix string) sq.Sqlizer {
+	return sq.Expr(prefix+"search_vector @@ news_tsquery(?)", text)
+}
+
+// textRank is the relevance of an item to text; higher is better.
+func textRank(text, prefix string) sq.Sqlizer {
+	return sq.Expr("ts_rank("+prefix+"search_vector, news_tsquery(?))::float8", text)
+}
+
+// matchFields are the text fields MatchedFields checks, with the expression
+// that matches each against news_tsquery($1). They use the same
+// configurations as search_vector.
+var matchFields = []struct {
+	name string
+	expr string
+}{
+	{"title", "(to_tsvector('russian', coalesce(title, '')) || to_tsvector('english', coalesce(title, ''))) @@ news_tsquery($1)"},
+	{"description", "(to_tsvector('russian', coalesce(description, '')) || to_tsvector('english', coalesce(description, ''))) @@ news_tsquery($1)"},
+	{"full_text", "(to_tsvector('russian', coalesce(full_text, '')) || to_tsvector('english', coalesce(full_text, ''))) @@ news_tsquery($1)"},
+}
+
+// MatchedFields returns, per item id, the names of the text fields that
+// match text. Items without a matching field are left out.
+func (r *PgRepository) MatchedFields(ctx context.Context, ids []string, text string) (map[string][]string, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	columns := make([]string, 0, len(matchFields))
+	for _, field := range matchFields {
+		columns = append(columns, field.expr)
+	}
+	query := "SELECT id, " + strings.Join(columns, ", ") + " FROM news WHERE id = ANY($2)"
+
+	rows, err := tx.Query(ctx, query, text, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matched := make(map[string][]string, len(ids))
+	for rows.Next() {
+		var id string
+		found := make([]bool, len(matchFields))
+		dest := []interface{}{&id}
+		for i := range found {
+			dest = append(dest, &found[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, field := range matchFields {
+			if found[i] {
+				matched[id] = append(matched[id], field.name)
+			}
+		}
+	}
+
+	return matched, rows.Err()
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/pgvector/pgvector-go"
+	"newstrix/internal/models"
+	"strings"
+)
+
+// CountToReembed returns how many items have no vector from model.
+func (r *PgRepository) CountToReembed(ctx context.Context, model string) (int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var count int
+	err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM news WHERE embedding_model IS DISTINCT FROM $1", model).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count news to re-embed: %w", err)
+	}
+	return count, nil
+}
+
+// NewsToReembed returns up to limit items with id greater than after that
+// have no vector from model, ordered by id.
+func (r *PgRepository) NewsToReembed(ctx context.Context, model string, after string, limit int) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "SELECT " + strings.Join(newsColumns, ", ") + ` FROM news
+		WHERE embedding_model IS DISTINCT FROM $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`
+	rows, err := tx.Query(ctx, query, model, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news to re-embed: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.NewsItem
+	for rows.Next() {
+		item, err := scanNews(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+// UpdateEmbeddings replaces the vector and its model of the given items
+// without touching their content or revision, and marks them ready. Items
+// without a cluster get the one set on the item. Items whose stored content
+// hash differs from ContentHash were revised after their text was embedded;
+// they are left alone. It returns the items that were updated.
+func (r *PgRepository) UpdateEmbeddings(ctx context.Context, items []models.NewsItem) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `UPDATE news SET
+		vector = $2,
+		embedding_model = $3,
+		embedding_dim = $4,
+		cluster_id = COALESCE(cluster_id, $5),
+		embedding_status = 'ready',
+		embedding_error = NULL,
+		next_embedding_at = NULL
+		WHERE id = $1 AND content_hash IS NOT DISTINCT FROM $6`
+	var updated []models.NewsItem
+	for _, item := range items {
+		tag, err := tx.Exec(ctx, query,
+			item.Guid, pgvector.NewVector(item.Vector), item.EmbeddingModel, item.EmbeddingDim, nullString(item.ClusterID), nullString(item.ContentHash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update embedding of %s: %w", item.Guid, err)
+		}
+		if tag.RowsAffected() > 0 {
+			updated = append(updated, item)
+		}
+	}
+
+	return updated, nil
+}
+
+// GetReembedProgress returns the saved position of a run towards model, or
+// nil if there is none.
+func (r *PgRepository) GetReembedProgress(ctx context.Context, model string) (*models.ReembedProgress, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var p models.ReembedProgress
+	err := tx.QueryRow(ctx, "SELECT model, last_id, processed, failed, started_at, updated_at FROM reembed_progress WHERE model = $1", model).
+		Scan(&p.Model, &p.LastID, &p.Processed, &p.Failed, &p.StartedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get re-embedding progress for %s: %w", model, err)
+	}
+
+	return &p, nil
+}
+
+func (r *PgRepository) SaveReembedProgress(ctx context.Context, p models.ReembedProgress) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `INSERT INTO reembed_progress (model, last_id, processed, failed, started_at, updated_at) VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (model) DO UPDATE SET
+			last_id = EXCLUDED.last_id,
+			processed = EXCLUDED.processed,
+			failed = EXCLUDED.failed,
+			updated_at = NOW()`
+	if _, err := tx.Exec(ctx, query, p.Model, p.LastID, p.Processed, p.Failed, p.StartedAt); err != nil {
+		return fmt.Errorf("failed to save re-embedding progress for %s: %w", p.Model, err)
+	}
+
+	return nil
+}
+
+func (r *PgRepository) DeleteReembedProgress(ctx context.Context, model string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM reembed_progress WHERE model = $1", model); err != nil {
+		return fmt.Errorf("failed to delete re-embedding progress for %s: %w", model, err)
+	}
+
+	return nil
+}
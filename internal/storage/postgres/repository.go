@@ -6,6 +6,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v4"
 	"github.com/pgvector/pgvector-go"
+	"math"
 	"newstrix/internal/models"
 	"strings"
 	"time"
@@ -19,21 +20,50 @@ func NewPgRepository(txManager TransactionManager) *PgRepository {
 	return &PgRepository{txManager: txManager}
 }
 
+var newsColumns = []string{"id", "title", "link", "description", "full_text", "published_at", "publisher", "vector", "cluster_id", "embedding_model", "embedding_dim", "embedding_status", "content_hash", "revision"}
+
+// newsInsertColumns are written by AddNews; revision is maintained by the
+// database.
+var newsInsertColumns = newsColumns[:len(newsColumns)-1]
+
+// AddNews inserts new items and replaces stored ones whose content hash
+// changed, bumping their revision. Callers archive the previous version with
+// ArchiveRevisions first.
 func (r *PgRepository) AddNews(ctx context.Context, news []models.NewsItem) error {
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
-	query := "INSERT INTO news (id, title, link, description, published_at, publisher, vector) VALUES "
+	query := "INSERT INTO news (" + strings.Join(newsInsertColumns, ", ") + ") VALUES "
 	values := []interface{}{}
 	placeholders := []string{}
 
 	for i, item := range news {
-		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7))
-		values = append(values, item.Guid, item.Title, item.Link, item.Description, item.PublishedAt, item.Publisher, pgvector.NewVector(item.Vector))
+		row := make([]string, len(newsInsertColumns))
+		for j := range newsInsertColumns {
+			row[j] = fmt.Sprintf("$%d", i*len(newsInsertColumns)+j+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(row, ", ")+")")
+		values = append(values, item.Guid, item.Title, item.Link, item.Description, nullString(item.FullText), item.PublishedAt, item.Publisher, nullVector(item.Vector), nullString(item.ClusterID), nullString(item.EmbeddingModel), item.EmbeddingDim, embeddingStatus(item), nullString(item.ContentHash))
 	}
 
 	query += strings.Join(placeholders, ", ")
-	query += " ON CONFLICT (id) DO NOTHING"
+	query += ` ON CONFLICT (id) DO UPDATE SET
+		title = EXCLUDED.title,
+		description = EXCLUDED.description,
+		full_text = EXCLUDED.full_text,
+		published_at = EXCLUDED.published_at,
+		vector = EXCLUDED.vector,
+		embedding_model = EXCLUDED.embedding_model,
+		embedding_dim = EXCLUDED.embedding_dim,
+		embedding_status = EXCLUDED.embedding_status,
+		embedding_attempts = 0,
+		embedding_error = NULL,
+		next_embedding_at = NULL,
+		cluster_id = COALESCE(news.cluster_id, EXCLUDED.cluster_id),
+		content_hash = EXCLUDED.content_hash,
+		revision = news.revision + 1,
+		updated_at = NOW()
+		WHERE news.content_hash IS DISTINCT FROM EXCLUDED.content_hash`
 
 	_, err := tx.Exec(ctx, query, values...)
 	if err != nil {
@@ -60,49 +90,71 @@ func (r *PgRepository) GetByID(ctx context.Context, id string) (*models.NewsItem
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
-	query := "SELECT id, title, link, description, published_at, publisher, vector FROM news WHERE id = $1"
+	query := "SELECT " + strings.Join(newsColumns, ", ") + " FROM news WHERE id = $1"
 	row := tx.QueryRow(ctx, query, id)
 
-	var item models.NewsItem
-	var v pgvector.Vector
-	if err := row.Scan(&item.Guid, &item.Title, &item.Link, &item.Description, &item.PublishedAt, &item.Publisher, &v); err != nil {
-		return nil, err
-	}
-	item.Vector = v.Slice()
-	return &item, nil
+	return scanNews(row)
 }
 
 func (r *PgRepository) SearchByFilters(ctx context.Context, opt models.SearchParams) ([]models.NewsItem, error) {
-	tx := r.txManager.GetQueryEngine(ctx)
-	qb := sq.Select("id", "title", "link", "description", "published_at", "publisher", "vector").
+	if opt.Mode == models.SearchModeChunks && opt.Vector != nil && len(*opt.Vector) > 0 {
+		return r.searchChunks(ctx, opt)
+	}
+
+	qb := sq.Select(newsColumns...).
 		From("news").
 		Limit(uint64(opt.Limit)).
 		PlaceholderFormat(sq.Dollar)
-
-	if opt.Keywords != nil && len(*opt.Keywords) > 0 {
-		for _, kw := range *opt.Keywords {
-			qb = qb.Where(sq.Or{
-				sq.Expr("title ILIKE ?", "%"+kw+"%"),
-				sq.Expr("description ILIKE ?", "%"+kw+"%"),
-			})
+	qb = applyNewsFilters(qb, opt, "")
+
+	// Every ordering ends with id, so that hits with equal keys keep their
+	// order between pages and opt.After is unambiguous.
+	scored := true
+	vector := opt.Vector != nil && len(*opt.Vector) > 0
+	switch {
+	case vector:
+		distance := vectorDistance(opt.Metric, "vector", *opt.Vector)
+		qb = qb.Column(sq.Alias(distance, "score")).
+			Where(sq.Eq{"embedding_model": opt.Model}).
+			OrderBy("score", "id")
+		if cutoff := maxDistance(opt); !math.IsInf(cutoff, 1) {
+			qb = qb.Where(sq.Expr("? <= ?", distance, cutoff))
+		}
+		if opt.After != nil {
+			qb = qb.Where(sq.Expr("(?, id) > (?, ?)", distance, opt.After.Score, opt.After.ID))
+		}
+	case opt.Keywords != nil && len(*opt.Keywords) > 0:
+		rank := textRank(strings.Join(*opt.Keywords, " "), "")
+		qb = qb.Column(sq.Alias(rank, "score")).
+			OrderBy("score DESC", "id DESC")
+		if opt.After != nil {
+			qb = qb.Where(sq.Expr("(?, id) < (?, ?)", rank, opt.After.Score, opt.After.ID))
+		}
+	default:
+		scored = false
+		qb = qb.OrderBy("published_at DESC", "id DESC")
+		if opt.After != nil {
+			qb = qb.Where("(published_at, id) < (?, ?)", opt.After.PublishedAt, opt.After.ID)
 		}
 	}
 
-	if opt.Source != nil && *opt.Source != "" {
-		qb = qb.Where(sq.Eq{"publisher": *opt.Source})
-	}
-
-	if opt.From != nil {
-		qb = qb.Where(sq.GtOrEq{"published_at": *opt.From})
+	if !vector {
+		return r.queryNews(ctx, qb, scored)
 	}
 
-	if opt.To != nil {
-		qb = qb.Where(sq.LtOrEq{"published_at": *opt.To})
-	}
+	var items []models.NewsItem
+	err := r.rankByVector(ctx, opt.Limit, opt.Limit, opt.After == nil && !hasNewsFilters(opt), func(bool) (int, error) {
+		var err error
+		items, err = r.queryNews(ctx, qb, scored)
+		return len(items), err
+	})
+	return items, err
+}
 
-	if opt.Vector != nil && len(*opt.Vector) > 0 {
-		qb = qb.OrderBy(fmt.Sprintf("vector <-> '%v'", pgvector.NewVector(*opt.Vector)))
-	}
+// queryNews runs qb, which selects newsColumns followed by the score when
+// scored is set.
+func (r *PgRepository) queryNews(ctx context.Context, qb sq.SelectBuilder, scored bool) ([]models.NewsItem, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
 
 	query, args, err := qb.ToSql()
 	if err != nil {
@@ -117,24 +169,121 @@ func (r *PgRepository) SearchByFilters(ctx context.Context, opt models.SearchPar
 
 	var items []models.NewsItem
 	for rows.Next() {
-		var item models.NewsItem
-		var v pgvector.Vector
-		if err := rows.Scan(
-			&item.Guid,
-			&item.Title,
-			&item.Link,
-			&item.Description,
-			&item.PublishedAt,
-			&item.Publisher,
-			&v,
-		); err != nil {
+		var extra []interface{}
+		var score float64
+		if scored {
+			extra = append(extra, &score)
+		}
+		item, err := scanNews(rows, extra...)
+		if err != nil {
 			return nil, err
 		}
-		item.Vector = v.Slice()
-		items = append(items, item)
+		item.Score = score
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+// vectorDistance returns the pgvector distance under metric between column
+// and vector, passed as a bind parameter; lower is more similar. column is
+// cast to the dimension of vector so that the per-model HNSW indexes, built
+// on that expression, can serve the ordering.
+func vectorDistance(metric, column string, vector []float32) sq.Sqlizer {
+	operator := "<=>"
+	switch metric {
+	case models.MetricInnerProduct:
+		operator = "<#>"
+	case models.MetricL2:
+		operator = "<->"
 	}
+	return sq.Expr(fmt.Sprintf("%s::vector(%d) %s ?", column, len(vector), operator), pgvector.NewVector(vector))
+}
 
-	return items, nil
+// maxDistance is the distance cutoff for opt.MinScore, +Inf without one.
+func maxDistance(opt models.SearchParams) float64 {
+	if opt.MinScore == nil {
+		return math.Inf(1)
+	}
+	return models.MaxDistance(opt.Metric, *opt.MinScore)
+}
+
+// applyNewsFilters adds the keyword, source and date filters of opt. prefix
+// qualifies the news columns, e.g. "n.".
+func applyNewsFilters(qb sq.SelectBuilder, opt models.SearchParams, prefix string) sq.SelectBuilder {
+	if opt.Keywords != nil && len(*opt.Keywords) > 0 {
+		for _, kw := range *opt.Keywords {
+			qb = qb.Where(textMatch(kw, prefix))
+		}
+	}
+
+	if opt.Source != nil && *opt.Source != "" {
+		qb = qb.Where(sq.Eq{prefix + "publisher": *opt.Source})
+	}
+
+	if opt.From != nil {
+		qb = qb.Where(sq.GtOrEq{prefix + "published_at": *opt.From})
+	}
+
+	if opt.To != nil {
+		qb = qb.Where(sq.LtOrEq{prefix + "published_at": *opt.To})
+	}
+
+	return qb
+}
+
+// hasNewsFilters reports whether applyNewsFilters restricts the rows for opt.
+func hasNewsFilters(opt models.SearchParams) bool {
+	return (opt.Keywords != nil && len(*opt.Keywords) > 0) ||
+		(opt.Source != nil && *opt.Source != "") ||
+		opt.From != nil || opt.To != nil
+}
+
+// scanNews reads a row selected with newsColumns followed by the extra
+// columns.
+func scanNews(row pgx.Row, extra ...interface{}) (*models.NewsItem, error) {
+	var item models.NewsItem
+	var fullText, clusterID, embeddingModel, contentHash *string
+	var embeddingDim *int
+	var v *pgvector.Vector
+	dest := []interface{}{
+		&item.Guid,
+		&item.Title,
+		&item.Link,
+		&item.Description,
+		&fullText,
+		&item.PublishedAt,
+		&item.Publisher,
+		&v,
+		&clusterID,
+		&embeddingModel,
+		&embeddingDim,
+		&item.EmbeddingStatus,
+		&contentHash,
+		&item.Revision,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if contentHash != nil {
+		item.ContentHash = *contentHash
+	}
+	if embeddingModel != nil {
+		item.EmbeddingModel = *embeddingModel
+	}
+	if embeddingDim != nil {
+		item.EmbeddingDim = *embeddingDim
+	}
+	if fullText != nil {
+		item.FullText = *fullText
+	}
+	if clusterID != nil {
+		item.ClusterID = *clusterID
+	}
+	if v != nil {
+		item.Vector = v.Slice()
+	}
+	return &item, nil
 }
 
 func (r *PgRepository) GetSourceLastParsed(ctx context.Context, source string) (time.Time, error) {
@@ -153,3 +302,193 @@ func (r *PgRepository) GetSourceLastParsed(ctx context.Context, source string) (
 
 	return lastParsed, nil
 }
+
+// StoredHashes returns the content hash of every stored item among ids. Items
+// stored without a hash map to an empty string.
+func (r *PgRepository) StoredHashes(ctx context.Context, ids []string) (map[string]string, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT id, COALESCE(content_hash, '') FROM news WHERE id = ANY($1)", ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing news: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, err
+		}
+		hashes[id] = hash
+	}
+
+	return hashes, rows.Err()
+}
+
+// LinkOwners returns the id of the stored item for every link that exists.
+func (r *PgRepository) LinkOwners(ctx context.Context, links []string) (map[string]string, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT link, id FROM news WHERE link = ANY($1)", links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up news links: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[string]string, len(links))
+	for rows.Next() {
+		var link, id string
+		if err := rows.Scan(&link, &id); err != nil {
+			return nil, err
+		}
+		owners[link] = id
+	}
+
+	return owners, rows.Err()
+}
+
+func (r *PgRepository) GetSourceValidators(ctx context.Context, source string) (models.CacheValidators, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "SELECT etag, last_modified FROM source_http_cache WHERE source_id = $1"
+	row := tx.QueryRow(ctx, query, source)
+
+	var etag, lastModified *string
+	if err := row.Scan(&etag, &lastModified); err != nil {
+		if err == pgx.ErrNoRows {
+			return models.CacheValidators{}, nil
+		}
+		return models.CacheValidators{}, fmt.Errorf("failed to get cache validators for source %s: %w", source, err)
+	}
+
+	var validators models.CacheValidators
+	if etag != nil {
+		validators.ETag = *etag
+	}
+	if lastModified != nil {
+		validators.LastModified = *lastModified
+	}
+	return validators, nil
+}
+
+func (r *PgRepository) UpdateSourceValidators(ctx context.Context, source string, validators models.CacheValidators) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `INSERT INTO source_http_cache (source_id, etag, last_modified, updated_at) VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (source_id) DO UPDATE SET etag = EXCLUDED.etag, last_modified = EXCLUDED.last_modified, updated_at = EXCLUDED.updated_at`
+	_, err := tx.Exec(ctx, query, source, nullString(validators.ETag), nullString(validators.LastModified))
+	if err != nil {
+		return fmt.Errorf("failed to update cache validators for source %s: %w", source, err)
+	}
+
+	return nil
+}
+
+const sourceHealthColumns = "source_id, last_success_at, last_error_at, last_error, consecutive_failures, total_runs, total_failures, avg_latency_ms, avg_items_per_run, quarantined_until"
+
+func (r *PgRepository) GetSourceHealth(ctx context.Context, source string) (*models.SourceHealth, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "SELECT " + sourceHealthColumns + " FROM source_health WHERE source_id = $1"
+	health, err := scanSourceHealth(tx.QueryRow(ctx, query, source))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &models.SourceHealth{Source: source}, nil
+		}
+		return nil, fmt.Errorf("failed to get health for source %s: %w", source, err)
+	}
+
+	return health, nil
+}
+
+func (r *PgRepository) SaveSourceHealth(ctx context.Context, h *models.SourceHealth) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "INSERT INTO source_health (" + sourceHealthColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (source_id) DO UPDATE SET
+			last_success_at = EXCLUDED.last_success_at,
+			last_error_at = EXCLUDED.last_error_at,
+			last_error = EXCLUDED.last_error,
+			consecutive_failures = EXCLUDED.consecutive_failures,
+			total_runs = EXCLUDED.total_runs,
+			total_failures = EXCLUDED.total_failures,
+			avg_latency_ms = EXCLUDED.avg_latency_ms,
+			avg_items_per_run = EXCLUDED.avg_items_per_run,
+			quarantined_until = EXCLUDED.quarantined_until`
+	_, err := tx.Exec(ctx, query,
+		h.Source, h.LastSuccessAt, h.LastErrorAt, nullString(h.LastError),
+		h.ConsecutiveFailures, h.TotalRuns, h.TotalFailures,
+		h.AvgLatencyMs, h.AvgItemsPerRun, h.QuarantinedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to save health for source %s: %w", h.Source, err)
+	}
+
+	return nil
+}
+
+func (r *PgRepository) ListSourceHealth(ctx context.Context) ([]models.SourceHealth, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT "+sourceHealthColumns+" FROM source_health ORDER BY source_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source health: %w", err)
+	}
+	defer rows.Close()
+
+	var list []models.SourceHealth
+	for rows.Next() {
+		health, err := scanSourceHealth(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *health)
+	}
+
+	return list, rows.Err()
+}
+
+func scanSourceHealth(row pgx.Row) (*models.SourceHealth, error) {
+	var h models.SourceHealth
+	var lastError *string
+	if err := row.Scan(
+		&h.Source,
+		&h.LastSuccessAt,
+		&h.LastErrorAt,
+		&lastError,
+		&h.ConsecutiveFailures,
+		&h.TotalRuns,
+		&h.TotalFailures,
+		&h.AvgLatencyMs,
+		&h.AvgItemsPerRun,
+		&h.QuarantinedUntil,
+	); err != nil {
+		return nil, err
+	}
+	if lastError != nil {
+		h.LastError = *lastError
+	}
+	return &h, nil
+}
+
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullVector stores items without a vector, e.g. pending ones, as NULL.
+func nullVector(v []float32) interface{} {
+	if len(v) == 0 {
+		return nil
+	}
+	return pgvector.NewVector(v)
+}
+
+func embeddingStatus(item models.NewsItem) string {
+	if len(item.Vector) == 0 {
+		return models.EmbeddingPending
+	}
+	return models.EmbeddingReady
+}
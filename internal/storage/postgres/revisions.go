@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"newstrix/internal/models"
+)
+
+// ArchiveRevisions copies the stored version of every item whose content hash
+// differs from the incoming one into news_revisions.
+func (r *PgRepository) ArchiveRevisions(ctx context.Context, news []models.NewsItem) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	ids := make([]string, 0, len(news))
+	hashes := make([]string, 0, len(news))
+	for _, item := range news {
+		ids = append(ids, item.Guid)
+		hashes = append(hashes, item.ContentHash)
+	}
+
+	query := `INSERT INTO news_revisions (news_id, revision, title, description, full_text, content_hash, published_at)
+		SELECT n.id, n.revision, n.title, n.description, n.full_text, n.content_hash, n.published_at
+		FROM news n
+		JOIN unnest($1::text[], $2::text[]) AS incoming (id, content_hash) ON incoming.id = n.id
+		WHERE n.content_hash IS DISTINCT FROM incoming.content_hash
+		ON CONFLICT (news_id, revision) DO NOTHING`
+	if _, err := tx.Exec(ctx, query, ids, hashes); err != nil {
+		return fmt.Errorf("failed to archive news revisions: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevisions returns all versions of an item, oldest first, with the
+// current one last. It returns no revisions if the item does not exist.
+func (r *PgRepository) GetRevisions(ctx context.Context, id string) ([]models.NewsRevision, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `SELECT revision, title, COALESCE(description, ''), COALESCE(full_text, ''), COALESCE(content_hash, ''), published_at, superseded_at, FALSE
+		FROM news_revisions WHERE news_id = $1
+		UNION ALL
+		SELECT revision, title, COALESCE(description, ''), COALESCE(full_text, ''), COALESCE(content_hash, ''), published_at, NULL, TRUE
+		FROM news WHERE id = $1
+		ORDER BY 1`
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions of %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	var revisions []models.NewsRevision
+	for rows.Next() {
+		var rev models.NewsRevision
+		if err := rows.Scan(
+			&rev.Revision,
+			&rev.Title,
+			&rev.Description,
+			&rev.FullText,
+			&rev.ContentHash,
+			&rev.PublishedAt,
+			&rev.SupersededAt,
+			&rev.Current,
+		); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
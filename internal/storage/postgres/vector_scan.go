@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// hnswDefaultEfSearch and hnswMaxEfSearch are the default and the largest
+	// hnsw.ef_search of pgvector.
+	hnswDefaultEfSearch = 40
+	hnswMaxEfSearch     = 1000
+)
+
+// rankByVector runs query, a vector search for limit rows, so that the
+// approximate HNSW indexes do not silently cut its results short. An index
+// scan yields at most hnsw.ef_search candidates, and filters, cursors and
+// score cutoffs only drop rows after it. So when useIndex is set, ef_search is
+// raised to cover the candidates query needs, and a page that still comes
+// back short is ranked again with index scans turned off, exactly. Without
+// useIndex, or when ef_search cannot cover the candidates, it is ranked
+// exactly right away. query reports how many rows it returned.
+//
+// The settings are local to the transaction, so ctx must carry one.
+func (r *PgRepository) rankByVector(ctx context.Context, limit, candidates int, useIndex bool, query func(exact bool) (int, error)) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	if useIndex && candidates <= hnswMaxEfSearch {
+		efSearch := max(candidates, hnswDefaultEfSearch)
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", efSearch)); err != nil {
+			return fmt.Errorf("failed to set hnsw.ef_search: %w", err)
+		}
+		n, err := query(false)
+		if err != nil || n >= limit {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "SET LOCAL enable_indexscan = off"); err != nil {
+		return fmt.Errorf("failed to disable index scans: %w", err)
+	}
+	_, err := query(true)
+	return err
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE source_http_cache (
+                      source_id TEXT PRIMARY KEY,
+                      etag TEXT,
+                      last_modified TEXT,
+                      updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS source_http_cache;
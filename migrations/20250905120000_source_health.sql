@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE source_health (
+                      source_id TEXT PRIMARY KEY,
+                      last_success_at TIMESTAMPTZ,
+                      last_error_at TIMESTAMPTZ,
+                      last_error TEXT,
+                      consecutive_failures INT NOT NULL DEFAULT 0,
+                      total_runs INT NOT NULL DEFAULT 0,
+                      total_failures INT NOT NULL DEFAULT 0,
+                      avg_latency_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+                      avg_items_per_run DOUBLE PRECISION NOT NULL DEFAULT 0,
+                      quarantined_until TIMESTAMPTZ
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS source_health;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE news ADD COLUMN cluster_id TEXT;
+CREATE INDEX news_cluster_id_idx ON news (cluster_id);
+CREATE INDEX news_published_at_idx ON news (published_at);
+CREATE TABLE story_clusters (
+                      id TEXT PRIMARY KEY,
+                      canonical_id TEXT NOT NULL,
+                      first_published_at TIMESTAMPTZ,
+                      last_published_at TIMESTAMPTZ,
+                      size INT NOT NULL DEFAULT 1
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS story_clusters;
+DROP INDEX IF EXISTS news_published_at_idx;
+DROP INDEX IF EXISTS news_cluster_id_idx;
+ALTER TABLE news DROP COLUMN IF EXISTS cluster_id;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE news ADD COLUMN content_hash TEXT;
+ALTER TABLE news ADD COLUMN revision INT NOT NULL DEFAULT 1;
+ALTER TABLE news ADD COLUMN updated_at TIMESTAMPTZ;
+CREATE TABLE news_revisions (
+                      news_id TEXT NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+                      revision INT NOT NULL,
+                      title TEXT NOT NULL,
+                      description TEXT,
+                      full_text TEXT,
+                      content_hash TEXT,
+                      published_at TIMESTAMPTZ,
+                      superseded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                      PRIMARY KEY (news_id, revision)
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS news_revisions;
+ALTER TABLE news DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE news DROP COLUMN IF EXISTS revision;
+ALTER TABLE news DROP COLUMN IF EXISTS content_hash;
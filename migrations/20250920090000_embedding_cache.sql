@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE embedding_cache (
+                      model TEXT NOT NULL,
+                      text_hash TEXT NOT NULL,
+                      vector VECTOR NOT NULL,
+                      created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                      PRIMARY KEY (model, text_hash)
+);
+CREATE INDEX embedding_cache_created_at_idx ON embedding_cache (created_at);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS embedding_cache;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE news ADD COLUMN embedding_model TEXT;
+ALTER TABLE news ADD COLUMN embedding_dim INT;
+-- Every vector stored so far came from the default model.
+UPDATE news SET embedding_model = 'ollama:bge-m3:latest', embedding_dim = 1024 WHERE vector IS NOT NULL;
+ALTER TABLE news ALTER COLUMN vector TYPE VECTOR;
+CREATE INDEX news_embedding_model_idx ON news (embedding_model);
+CREATE TABLE reembed_progress (
+                      model TEXT PRIMARY KEY,
+                      last_id TEXT NOT NULL DEFAULT '',
+                      processed INT NOT NULL DEFAULT 0,
+                      failed INT NOT NULL DEFAULT 0,
+                      started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                      updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS reembed_progress;
+DROP INDEX IF EXISTS news_embedding_model_idx;
+ALTER TABLE news ALTER COLUMN vector TYPE VECTOR(1024);
+ALTER TABLE news DROP COLUMN IF EXISTS embedding_dim;
+ALTER TABLE news DROP COLUMN IF EXISTS embedding_model;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE news_chunks (
+                      news_id TEXT NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+                      chunk_index INT NOT NULL,
+                      text TEXT NOT NULL,
+                      vector VECTOR NOT NULL,
+                      embedding_model TEXT NOT NULL,
+                      embedding_dim INT NOT NULL,
+                      PRIMARY KEY (news_id, chunk_index)
+);
+CREATE INDEX news_chunks_embedding_model_idx ON news_chunks (embedding_model);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS news_chunks;
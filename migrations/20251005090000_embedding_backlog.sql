@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE news ADD COLUMN embedding_status TEXT NOT NULL DEFAULT 'ready';
+ALTER TABLE news ADD COLUMN embedding_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE news ADD COLUMN embedding_error TEXT;
+ALTER TABLE news ADD COLUMN next_embedding_at TIMESTAMPTZ;
+ALTER TABLE news ADD CONSTRAINT news_embedding_status_check CHECK (embedding_status IN ('pending', 'ready'));
+CREATE INDEX news_embedding_pending_idx ON news (next_embedding_at) WHERE embedding_status = 'pending';
+
+
+-- +goose Down
+DROP INDEX IF EXISTS news_embedding_pending_idx;
+ALTER TABLE news DROP CONSTRAINT IF EXISTS news_embedding_status_check;
+ALTER TABLE news DROP COLUMN IF EXISTS next_embedding_at;
+ALTER TABLE news DROP COLUMN IF EXISTS embedding_error;
+ALTER TABLE news DROP COLUMN IF EXISTS embedding_attempts;
+ALTER TABLE news DROP COLUMN IF EXISTS embedding_status;
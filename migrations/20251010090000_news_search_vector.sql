@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE news ADD COLUMN search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('russian', coalesce(full_text, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(full_text, '')), 'C')
+    ) STORED;
+CREATE INDEX news_search_vector_idx ON news USING GIN (search_vector);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS news_search_vector_idx;
+ALTER TABLE news DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- news_tsquery parses a query typed by a user (quotes, OR, -word) with the
+-- same configurations as news.search_vector.
+-- +goose StatementBegin
+CREATE FUNCTION news_tsquery(query TEXT) RETURNS TSQUERY
+    LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE
+AS $$
+    SELECT websearch_to_tsquery('russian', query) || websearch_to_tsquery('english', query)
+$$;
+-- +goose StatementEnd
+
+
+-- +goose Down
+DROP FUNCTION IF EXISTS news_tsquery(TEXT);
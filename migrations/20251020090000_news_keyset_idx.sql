@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX news_published_at_id_idx ON news (published_at DESC, id DESC);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS news_published_at_id_idx;
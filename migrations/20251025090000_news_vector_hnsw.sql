@@ -0,0 +1,13 @@
+-- +goose Up
+-- news.vector is untyped so that vectors of several models can live side by
+-- side while re-embedding. pgvector only indexes vectors of one dimension, so
+-- every model gets a partial HNSW index on its vectors cast to its dimension;
+-- searches order by the same expression. Other models and metrics need an
+-- index of their own, see README.
+CREATE INDEX news_vector_bge_m3_cosine_idx ON news
+    USING hnsw ((vector::vector(1024)) vector_cosine_ops)
+    WHERE embedding_model = 'ollama:bge-m3:latest';
+
+
+-- +goose Down
+DROP INDEX IF EXISTS news_vector_bge_m3_cosine_idx;
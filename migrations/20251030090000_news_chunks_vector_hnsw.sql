@@ -0,0 +1,11 @@
+-- +goose Up
+-- Like news.vector, news_chunks.vector is untyped; every model gets a partial
+-- HNSW index on its chunk vectors cast to its dimension. Passage search takes
+-- its candidates from it.
+CREATE INDEX news_chunks_vector_bge_m3_cosine_idx ON news_chunks
+    USING hnsw ((vector::vector(1024)) vector_cosine_ops)
+    WHERE embedding_model = 'ollama:bge-m3:latest';
+
+
+-- +goose Down
+DROP INDEX IF EXISTS news_chunks_vector_bge_m3_cosine_idx;